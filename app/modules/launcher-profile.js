@@ -0,0 +1,79 @@
+/**
+ * Launcher Profile
+ * Bestimmt Profil und Datenbank, die der Server lädt, wenn ein Launcher sie vorgibt.
+ *
+ * Die Launcher übergeben das gewählte Profil in LTTH_PROFILE und dessen Datenbank in
+ * DATABASE_PATH. Das Profil wird als aktives Profil übernommen, damit Datenbank, Streamer-ID
+ * für die Datenzuordnung und der Profil-Umschalter im Dashboard übereinstimmen. Ein
+ * DATABASE_PATH, dessen Dateiname nicht zum aktiven Profil passt, wird abgelehnt.
+ */
+
+const path = require('path');
+
+/**
+ * Sanitize a profile name the same way as UserProfileManager.getProfilePath
+ * @param {string} name - Profile name
+ * @returns {string}
+ */
+function sanitizeProfileName(name) {
+    return name.replace(/[^a-zA-Z0-9_-]/g, '_');
+}
+
+/**
+ * Check whether a database file belongs to a profile (user_configs/<profile>.db or <dir>/<profile>.db)
+ * @param {string} dbPath - Database file
+ * @param {string} profile - Profile name
+ * @returns {boolean}
+ */
+function databaseMatchesProfile(dbPath, profile) {
+    const stem = path.basename(dbPath, path.extname(dbPath));
+    return sanitizeProfileName(stem) === sanitizeProfileName(profile);
+}
+
+/**
+ * Resolve the profile and database the server loads
+ * @param {Object} options - profileManager, activeProfile (from .active_profile), env, baseDir and logger
+ * @returns {{profile: string, dbPath: string}} Profile used as streamer ID and its database
+ */
+function resolveProfileDatabase({ profileManager, activeProfile, env = process.env, baseDir, logger }) {
+    let profile = activeProfile;
+
+    // Die Launcher übergeben den bereinigten Namen: "john.doe" aus .active_profile kommt als
+    // "john_doe" an und ist dasselbe Profil, das gespeicherte Original bleibt aktiv
+    const requested = (env.LTTH_PROFILE || '').trim();
+    if (requested && sanitizeProfileName(requested) !== requested) {
+        logger.warn(`⚠️ LTTH_PROFILE "${requested}" ist kein gültiger Profilname und wird ignoriert`);
+    } else if (requested && requested !== sanitizeProfileName(activeProfile)) {
+        logger.info(`👤 Profil vom Launcher vorgegeben: ${requested} (statt ${activeProfile})`);
+        profile = requested;
+    }
+
+    let dbPath = profileManager.getProfilePath(profile);
+    if (env.DATABASE_PATH) {
+        const candidate = path.resolve(baseDir, env.DATABASE_PATH);
+        if (databaseMatchesProfile(candidate, profile)) {
+            dbPath = candidate;
+            logger.info(`📁 DATABASE_PATH gesetzt, verwende: ${dbPath}`);
+        } else {
+            logger.error(`❌ DATABASE_PATH ${candidate} gehört nicht zum aktiven Profil "${profile}" und wird ignoriert`);
+        }
+    }
+
+    // Ein neues Profil in user_configs anlegen, damit es im Profil-Umschalter erscheint
+    const inUserConfigs = dbPath === profileManager.getProfilePath(profile);
+    if (inUserConfigs && !profileManager.profileExists(profile)) {
+        logger.info(`📝 Erstelle neues Profil: ${profile}`);
+        profileManager.createProfile(profile);
+    }
+    if (profile !== activeProfile && profileManager.profileExists(profile)) {
+        profileManager.setActiveProfile(profile);
+    }
+
+    return { profile, dbPath };
+}
+
+module.exports = {
+    sanitizeProfileName,
+    databaseMatchesProfile,
+    resolveProfileDatabase
+};
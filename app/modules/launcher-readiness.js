@@ -0,0 +1,121 @@
+/**
+ * Launcher Readiness Reporter
+ * Meldet dem Launcher die Startphasen des Servers (Datenbank, Plugins, Port, bereit),
+ * damit er sie im Splash Screen anzeigen und erst nach "ready" weiterleiten kann.
+ *
+ * Der Launcher übergibt per Umgebung:
+ * - LTTH_LAUNCHER_TOKEN: Einmal-Token, autorisiert die Meldungen
+ * - LTTH_READY_URL: Loopback-Callback (POST mit X-Launcher-Token) und/oder
+ * - LTTH_READY_FILE: JSON-Datei, die bei jeder Phase neu geschrieben wird
+ * Ohne diese Variablen (z.B. bei "npm start") passiert nichts.
+ */
+
+const fs = require('fs');
+const http = require('http');
+const path = require('path');
+
+const STAGES = ['starting', 'database', 'plugins', 'listening', 'ready'];
+const LOOPBACK_HOSTS = ['127.0.0.1', 'localhost', '[::1]'];
+const REQUEST_TIMEOUT_MS = 2000;
+
+class LauncherReadiness {
+    constructor(env = process.env) {
+        this.token = env.LTTH_LAUNCHER_TOKEN || '';
+        this.file = env.LTTH_READY_FILE || '';
+        this.url = null;
+        this.port = null;
+        this.version = this.readVersion();
+
+        // The token must never leave the machine
+        if (env.LTTH_READY_URL) {
+            try {
+                const url = new URL(env.LTTH_READY_URL);
+                if (url.protocol === 'http:' && LOOPBACK_HOSTS.includes(url.hostname)) {
+                    this.url = url;
+                }
+            } catch (error) {
+                // Invalid URL - callback disabled
+            }
+        }
+    }
+
+    get enabled() {
+        return Boolean(this.token && (this.url || this.file));
+    }
+
+    readVersion() {
+        try {
+            return require(path.join(__dirname, '..', 'package.json')).version || null;
+        } catch (error) {
+            return null;
+        }
+    }
+
+    /**
+     * Report a startup stage
+     * @param {string} stage - One of STAGES
+     * @param {Object} details - Optional port and message
+     * @returns {Promise<void>} Resolves once delivered; errors are ignored
+     */
+    report(stage, details = {}) {
+        if (!this.enabled || !STAGES.includes(stage)) {
+            return Promise.resolve();
+        }
+        if (details.port) {
+            this.port = details.port;
+        }
+
+        const payload = {
+            stage,
+            port: this.port,
+            version: this.version,
+            pid: process.pid,
+            message: details.message || '',
+            timestamp: Date.now()
+        };
+
+        if (this.file) {
+            this.writeFile(payload);
+        }
+        if (this.url) {
+            return this.post(payload);
+        }
+        return Promise.resolve();
+    }
+
+    writeFile(payload) {
+        // Write and rename, so the launcher never reads a half-written file
+        const tmpFile = `${this.file}.tmp`;
+        try {
+            fs.writeFileSync(tmpFile, JSON.stringify({ ...payload, token: this.token }));
+            fs.renameSync(tmpFile, this.file);
+        } catch (error) {
+            // Launcher falls back to its own health check
+        }
+    }
+
+    post(payload) {
+        return new Promise((resolve) => {
+            const body = JSON.stringify(payload);
+            const req = http.request(this.url, {
+                method: 'POST',
+                timeout: REQUEST_TIMEOUT_MS,
+                headers: {
+                    'Content-Type': 'application/json',
+                    'Content-Length': Buffer.byteLength(body),
+                    'X-Launcher-Token': this.token
+                }
+            }, (res) => {
+                res.resume();
+                res.on('end', resolve);
+            });
+            req.on('timeout', () => req.destroy());
+            req.on('error', () => resolve());
+            req.end(body);
+        });
+    }
+}
+
+module.exports = new LauncherReadiness();
+module.exports.LauncherReadiness = LauncherReadiness;
+module.exports.STAGES = STAGES;
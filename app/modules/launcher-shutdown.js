@@ -0,0 +1,105 @@
+/**
+ * Launcher Shutdown
+ * Beendet den Server sauber, wenn der Desktop-Launcher (oder Strg+C/SIGTERM) es verlangt,
+ * damit die SQLite-Datenbanken geschlossen werden, bevor der Prozess endet.
+ *
+ * Windows kennt kein SIGTERM für fensterlose Kindprozesse, deshalb ruft der Launcher
+ * POST /api/launcher/shutdown auf. Die Route nimmt nur Anfragen von 127.0.0.1/::1 an,
+ * die das Token aus LTTH_LAUNCHER_TOKEN im Header X-Launcher-Token mitschicken.
+ */
+
+const crypto = require('crypto');
+
+const LOOPBACK_ADDRESSES = ['127.0.0.1', '::1', '::ffff:127.0.0.1'];
+
+/**
+ * Check whether a socket address belongs to the local machine
+ * @param {string} address - req.socket.remoteAddress
+ * @returns {boolean}
+ */
+function isLoopbackAddress(address) {
+    return LOOPBACK_ADDRESSES.includes(address || '');
+}
+
+/**
+ * Compare the request token with the launcher token in constant time
+ * @param {string} expected - Token from LTTH_LAUNCHER_TOKEN
+ * @param {string} actual - X-Launcher-Token header
+ * @returns {boolean}
+ */
+function tokenMatches(expected, actual) {
+    if (!expected || typeof actual !== 'string') {
+        return false;
+    }
+    const a = Buffer.from(expected);
+    const b = Buffer.from(actual);
+    return a.length === b.length && crypto.timingSafeEqual(a, b);
+}
+
+/**
+ * Create the Express handler for POST /api/launcher/shutdown
+ * @param {Function} shutdown - Called with the reason 'launcher' after the response was sent
+ * @param {Object} env - Environment with LTTH_LAUNCHER_TOKEN
+ * @returns {Function} Express route handler
+ */
+function createShutdownRoute(shutdown, env = process.env) {
+    return (req, res) => {
+        if (!isLoopbackAddress(req.socket.remoteAddress) || !tokenMatches(env.LTTH_LAUNCHER_TOKEN, req.get('X-Launcher-Token'))) {
+            return res.status(403).json({ success: false, error: 'Forbidden' });
+        }
+        res.json({ success: true });
+        setImmediate(() => shutdown('launcher'));
+    };
+}
+
+/**
+ * Create the shutdown sequence: TikTok, OBS and Cloud Sync are disconnected first,
+ * then the database is closed and finally the HTTP server
+ * @param {Object} deps - logger, tiktok, obs, cloudSync, db, server and optional exit
+ * @returns {Function} gracefulShutdown(reason), runs only once
+ */
+function createGracefulShutdown({ logger, tiktok, obs, cloudSync, db, server, exit = process.exit }) {
+    let shuttingDown = false;
+
+    return async function gracefulShutdown(reason) {
+        // Ctrl+C reaches launch.js and the server, launch.js forwards it again
+        if (shuttingDown) {
+            return;
+        }
+        shuttingDown = true;
+        logger.info(`\n\n🛑 Shutting down gracefully (${reason})...`);
+
+        // TikTok-Verbindung trennen
+        if (tiktok.isActive()) {
+            tiktok.disconnect();
+        }
+
+        // OBS-Verbindung trennen
+        if (obs.isConnected()) {
+            await obs.disconnect();
+        }
+
+        // Cloud Sync beenden
+        try {
+            await cloudSync.shutdown();
+        } catch (error) {
+            logger.error('Error shutting down cloud sync:', error);
+        }
+
+        // Datenbank schließen
+        db.close();
+
+        // Server schließen
+        server.close(() => {
+            logger.info('✅ Server closed');
+            exit(0);
+        });
+    };
+}
+
+module.exports = {
+    isLoopbackAddress,
+    tokenMatches,
+    createShutdownRoute,
+    createGracefulShutdown
+};
@@ -365,18 +365,17 @@ class Launcher {
                 stdio: 'inherit' // Output direkt an Console
             });
 
-            // Cleanup bei Exit
+            // Cleanup bei Exit: Signal weiterleiten und warten, bis der Server
+            // Datenbank und Verbindungen geschlossen hat (Exit-Handler unten)
             process.on('SIGINT', () => {
                 this.log.newLine();
                 this.log.separator();
                 this.log.info('Server wird beendet...');
                 serverProcess.kill('SIGINT');
-                process.exit(0);
             });
 
             process.on('SIGTERM', () => {
                 serverProcess.kill('SIGTERM');
-                process.exit(0);
             });
 
             // Warte auf Server-Exit
@@ -510,6 +510,9 @@ class PluginLoader extends EventEmitter {
         // State laden
         this.state = this.loadState();
 
+        // Plugins, die der Launcher nach einem Absturz deaktiviert hat
+        this.disablePluginsFromLauncher(process.env.LTTH_DISABLE_PLUGINS);
+
         // TikTok module reference (set after TikTok module is initialized)
         // This allows dynamic registration of TikTok events when plugins are enabled at runtime
         this.tiktok = null;
@@ -636,6 +639,33 @@ class PluginLoader extends EventEmitter {
         return {};
     }
 
+    /**
+     * Deaktiviert Plugins, die der Launcher nach einem Absturz über LTTH_DISABLE_PLUGINS meldet.
+     * Der State wird gespeichert, sie bleiben also aus, bis sie im Dashboard wieder aktiviert werden.
+     * @param {string} list - Komma-getrennte Plugin-IDs
+     */
+    disablePluginsFromLauncher(list) {
+        if (!list) {
+            return;
+        }
+
+        let changed = false;
+        for (const pluginId of list.split(',').map(id => id.trim()).filter(Boolean)) {
+            if (this.state[pluginId] && this.state[pluginId].enabled === false) {
+                continue;
+            }
+            if (!this.state[pluginId]) {
+                this.state[pluginId] = {};
+            }
+            this.state[pluginId].enabled = false;
+            this.logger.warn(`🔌 Plugin ${pluginId} disabled by the launcher after it crashed the server`);
+            changed = true;
+        }
+        if (changed) {
+            this.saveState();
+        }
+    }
+
     /**
      * Speichert den Plugin-State in die Datei
      */
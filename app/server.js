@@ -85,6 +85,10 @@ const getSessionExtractor = () => {
 
 // Import New Modules
 const logger = require('./modules/logger');
+const launcherReadiness = require('./modules/launcher-readiness'); // Startup stages for the launcher splash screen
+const { createShutdownRoute, createGracefulShutdown } = require('./modules/launcher-shutdown');
+const { resolveProfileDatabase } = require('./modules/launcher-profile');
+launcherReadiness.report('starting');
 const debugLogger = require('./modules/debug-logger');
 const { apiLimiter, authLimiter, uploadLimiter, pluginLimiter, iftttLimiter } = require('./modules/rate-limiter');
 const OBSWebSocket = require('./modules/obs-websocket');
@@ -345,6 +349,7 @@ const upload = multer({
 
 
 // ========== USER PROFILE INITIALISIEREN ==========
+launcherReadiness.report('database', { message: 'Profil und Datenbank werden vorbereitet' });
 const profileManager = new UserProfileManager(configPathManager);
 
 logger.info('🔧 Initializing User Profile Manager...');
@@ -358,6 +363,7 @@ if (!activeProfile) {
     // Prüfe, ob eine alte database.db existiert (Migration)
     if (fs.existsSync(oldDbPath)) {
         logger.info('📦 Alte database.db gefunden - Migration wird durchgeführt...');
+        launcherReadiness.report('database', { message: 'Migration der alten Datenbank' });
         const defaultUsername = 'default';
         profileManager.migrateOldDatabase(defaultUsername);
         profileManager.setActiveProfile(defaultUsername);
@@ -389,13 +395,19 @@ if (!activeProfile) {
     }
 }
 
+// LTTH_PROFILE und DATABASE_PATH (vom Launcher-Profil gesetzt) haben Vorrang vor .active_profile,
+// ein DATABASE_PATH eines anderen Profils wird abgelehnt
+const launcherProfile = resolveProfileDatabase({ profileManager, activeProfile, baseDir: __dirname, logger });
+activeProfile = launcherProfile.profile;
+
 logger.info(`👤 Aktives User-Profil: ${activeProfile}`);
 
 // ========== INITIALIZATION STATE MANAGER ==========
 const initState = require('./modules/initialization-state');
 
 // ========== DATABASE INITIALISIEREN ==========
-const dbPath = profileManager.getProfilePath(activeProfile);
+const dbPath = launcherProfile.dbPath;
+fs.mkdirSync(path.dirname(dbPath), { recursive: true });
 const db = new Database(dbPath, activeProfile); // Pass streamer_id as activeProfile
 logger.info(`✅ Database initialized: ${dbPath}`);
 logger.info(`💡 All settings (including API keys) are stored here and will survive app updates!`);
@@ -507,6 +519,15 @@ app.use('/api/wiki', wikiRoutes);
 // NOTE: Plugin static files middleware will be registered AFTER plugins are loaded
 // to ensure plugin-registered routes take precedence over static file serving
 
+// ========== LAUNCHER ROUTES ==========
+
+/**
+ * POST /api/launcher/shutdown - Graceful shutdown requested by the desktop launcher
+ * The launcher passes a random token via LTTH_LAUNCHER_TOKEN; Windows has no SIGTERM for
+ * windowless child processes, so the launchers stop the server through this route.
+ */
+app.post('/api/launcher/shutdown', createShutdownRoute((reason) => gracefulShutdown(reason)));
+
 // ========== UPDATE ROUTES ==========
 
 // ========== I18N API ROUTES ==========
@@ -3049,6 +3070,7 @@ const pluginCacheControl = (req, res, next) => {
 (async () => {
     // Plugins laden VOR Server-Start, damit alle Routen verfügbar sind
     logger.info('🔌 Loading plugins...');
+    launcherReadiness.report('plugins');
     try {
         const plugins = await pluginLoader.loadAllPlugins();
         const loadedCount = pluginLoader.plugins.size;
@@ -3137,6 +3159,7 @@ const pluginCacheControl = (req, res, next) => {
     // Jetzt Server starten
     server.listen(PORT, async () => {
         initState.setServerStarted();
+        launcherReadiness.report('listening', { port: server.address().port });
 
         logger.info('\n' + '='.repeat(50));
         logger.info('✅ Pup Cids little TikTok Helper läuft!');
@@ -3151,6 +3174,9 @@ const pluginCacheControl = (req, res, next) => {
         logger.info('\n⌨️  Beenden:      Drücke Strg+C');
         logger.info('='.repeat(50) + '\n');
 
+        // All routes are registered and the dashboard is served - the launcher may redirect now
+        launcherReadiness.report('ready');
+
         // OBS WebSocket auto-connect (if configured)
     const obsConfigStr = db.getSetting('obs_websocket_config');
     if (obsConfigStr) {
@@ -3177,7 +3203,8 @@ const pluginCacheControl = (req, res, next) => {
 
     // TikTok auto-reconnect (if configured)
     const autoReconnectEnabled = db.getSetting('tiktok_auto_reconnect') !== 'false'; // Default to true
-    const savedUsername = db.getSetting('last_connected_username');
+    // TIKTOK_DEFAULT_USERNAME (z.B. vom Launcher-Profil) gilt, bis ein Stream verbunden wurde
+    const savedUsername = db.getSetting('last_connected_username') || process.env.TIKTOK_DEFAULT_USERNAME;
     
     if (autoReconnectEnabled && savedUsername) {
         logger.info(`🔄 Auto-Reconnect aktiviert: Versuche Verbindung zu @${savedUsername}...`);
@@ -3535,35 +3562,12 @@ const pluginCacheControl = (req, res, next) => {
 })(); // Schließe async IIFE
 
 // Graceful Shutdown
-process.on('SIGINT', async () => {
-    logger.info('\n\n🛑 Shutting down gracefully...');
+const gracefulShutdown = createGracefulShutdown({ logger, tiktok, obs, cloudSync, db, server });
 
-    // TikTok-Verbindung trennen
-    if (tiktok.isActive()) {
-        tiktok.disconnect();
-    }
-
-    // OBS-Verbindung trennen
-    if (obs.isConnected()) {
-        await obs.disconnect();
-    }
-
-    // Cloud Sync beenden
-    try {
-        await cloudSync.shutdown();
-    } catch (error) {
-        logger.error('Error shutting down cloud sync:', error);
-    }
-
-    // Datenbank schließen
-    db.close();
-
-    // Server schließen
-    server.close(() => {
-        logger.info('✅ Server closed');
-        process.exit(0);
-    });
-});
+process.on('SIGINT', () => gracefulShutdown('SIGINT'));
+process.on('SIGTERM', () => gracefulShutdown('SIGTERM'));
+// Windows: Ctrl+Break
+process.on('SIGBREAK', () => gracefulShutdown('SIGBREAK'));
 
 // Error Handling
 process.on('uncaughtException', (error) => {
@@ -0,0 +1,119 @@
+/**
+ * Tests for the profile and database selection by the launcher
+ *
+ * This test verifies that:
+ * 1. LTTH_PROFILE overrides .active_profile and becomes the active profile
+ * 2. DATABASE_PATH is only used if it belongs to the active profile
+ * 3. Profiles requested by the launcher are created in user_configs if missing
+ */
+
+const path = require('path');
+const {
+    sanitizeProfileName,
+    databaseMatchesProfile,
+    resolveProfileDatabase
+} = require('../modules/launcher-profile');
+
+const CONFIG_DIR = path.join(path.sep, 'ltth', 'user_configs');
+const BASE_DIR = path.join(path.sep, 'ltth', 'app');
+
+describe('Launcher Profile', () => {
+    let profileManager;
+    let logger;
+    let existing;
+
+    beforeEach(() => {
+        existing = new Set(['default', 'streamer_a']);
+        profileManager = {
+            getProfilePath: jest.fn((username) => path.join(CONFIG_DIR, `${sanitizeProfileName(username)}.db`)),
+            profileExists: jest.fn((username) => existing.has(username)),
+            createProfile: jest.fn((username) => existing.add(username)),
+            setActiveProfile: jest.fn()
+        };
+        logger = { info: jest.fn(), warn: jest.fn(), error: jest.fn() };
+    });
+
+    describe('databaseMatchesProfile', () => {
+        test.each([
+            [path.join(CONFIG_DIR, 'streamer_a.db'), 'streamer_a', true],
+            [path.join(path.sep, 'data', 'streamer_a.db'), 'streamer_a', true],
+            [path.join(CONFIG_DIR, 'streamer.a.db'), 'streamer.a', true],
+            [path.join(CONFIG_DIR, 'streamer_b.db'), 'streamer_a', false],
+            [path.join(CONFIG_DIR, 'default', 'database.db'), 'default', false]
+        ])('%s for profile %s is %s', (dbPath, profile, expected) => {
+            expect(databaseMatchesProfile(dbPath, profile)).toBe(expected);
+        });
+    });
+
+    describe('resolveProfileDatabase', () => {
+        test('keeps the active profile without launcher variables', () => {
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env: {}, baseDir: BASE_DIR, logger });
+
+            expect(result).toEqual({ profile: 'default', dbPath: path.join(CONFIG_DIR, 'default.db') });
+            expect(profileManager.setActiveProfile).not.toHaveBeenCalled();
+        });
+
+        test('LTTH_PROFILE overrides .active_profile and is saved as active profile', () => {
+            const env = { LTTH_PROFILE: 'streamer_a', DATABASE_PATH: path.join(CONFIG_DIR, 'streamer_a.db') };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env, baseDir: BASE_DIR, logger });
+
+            expect(result).toEqual({ profile: 'streamer_a', dbPath: path.join(CONFIG_DIR, 'streamer_a.db') });
+            expect(profileManager.setActiveProfile).toHaveBeenCalledWith('streamer_a');
+            expect(logger.error).not.toHaveBeenCalled();
+        });
+
+        test('keeps a dotted active profile the launcher passes sanitized', () => {
+            existing.add('john.doe');
+            const env = { LTTH_PROFILE: 'john_doe', DATABASE_PATH: path.join(CONFIG_DIR, 'john_doe.db') };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'john.doe', env, baseDir: BASE_DIR, logger });
+
+            expect(result).toEqual({ profile: 'john.doe', dbPath: path.join(CONFIG_DIR, 'john_doe.db') });
+            expect(profileManager.setActiveProfile).not.toHaveBeenCalled();
+            expect(profileManager.createProfile).not.toHaveBeenCalled();
+            expect(logger.info.mock.calls.some(([message]) => message.includes('Profil vom Launcher vorgegeben'))).toBe(false);
+            expect(logger.error).not.toHaveBeenCalled();
+        });
+
+        test('rejects a DATABASE_PATH of another profile', () => {
+            const env = { DATABASE_PATH: path.join(CONFIG_DIR, 'streamer_a.db') };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env, baseDir: BASE_DIR, logger });
+
+            expect(result).toEqual({ profile: 'default', dbPath: path.join(CONFIG_DIR, 'default.db') });
+            expect(logger.error).toHaveBeenCalled();
+        });
+
+        test('rejects a DATABASE_PATH that does not match LTTH_PROFILE', () => {
+            const env = { LTTH_PROFILE: 'streamer_a', DATABASE_PATH: path.join(CONFIG_DIR, 'default.db') };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env, baseDir: BASE_DIR, logger });
+
+            expect(result).toEqual({ profile: 'streamer_a', dbPath: path.join(CONFIG_DIR, 'streamer_a.db') });
+            expect(logger.error).toHaveBeenCalled();
+        });
+
+        test('resolves a relative DATABASE_PATH against the app directory', () => {
+            const env = { LTTH_PROFILE: 'streamer_a', DATABASE_PATH: 'data/streamer_a.db' };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env, baseDir: BASE_DIR, logger });
+
+            expect(result.dbPath).toBe(path.join(BASE_DIR, 'data', 'streamer_a.db'));
+            expect(profileManager.createProfile).not.toHaveBeenCalled();
+        });
+
+        test('creates a missing profile requested by the launcher', () => {
+            const env = { LTTH_PROFILE: 'new_streamer' };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env, baseDir: BASE_DIR, logger });
+
+            expect(result.profile).toBe('new_streamer');
+            expect(profileManager.createProfile).toHaveBeenCalledWith('new_streamer');
+            expect(profileManager.setActiveProfile).toHaveBeenCalledWith('new_streamer');
+        });
+
+        test('ignores an invalid LTTH_PROFILE', () => {
+            const env = { LTTH_PROFILE: '../other' };
+            const result = resolveProfileDatabase({ profileManager, activeProfile: 'default', env, baseDir: BASE_DIR, logger });
+
+            expect(result.profile).toBe('default');
+            expect(logger.warn).toHaveBeenCalled();
+            expect(profileManager.setActiveProfile).not.toHaveBeenCalled();
+        });
+    });
+});
@@ -0,0 +1,138 @@
+/**
+ * Test: Launcher Readiness Reporter
+ *
+ * Verifies that startup stages are only sent to loopback callback URLs, carry the launcher
+ * token and arrive in the order the launcher expects (starting -> ready).
+ */
+
+const fs = require('fs');
+const http = require('http');
+const os = require('os');
+const path = require('path');
+const { LauncherReadiness, STAGES } = require('../modules/launcher-readiness');
+
+describe('Launcher Readiness', () => {
+    describe('stages', () => {
+        test('match the order the launchers compare against', () => {
+            // stageIndex() in the launchers only lets stages advance in this order
+            expect(STAGES).toEqual(['starting', 'database', 'plugins', 'listening', 'ready']);
+        });
+    });
+
+    describe('callback URL filter', () => {
+        test.each([
+            ['http://127.0.0.1:8765/api/server/ready'],
+            ['http://localhost:8765/api/server/ready'],
+            ['http://[::1]:8765/api/server/ready']
+        ])('accepts loopback URL %s', (url) => {
+            const readiness = new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret', LTTH_READY_URL: url });
+            expect(readiness.url).not.toBeNull();
+            expect(readiness.enabled).toBe(true);
+        });
+
+        test.each([
+            ['http://192.168.1.20:8765/api/server/ready'],
+            ['http://example.com/api/server/ready'],
+            ['http://127.0.0.1.example.com/api/server/ready'],
+            ['https://127.0.0.1:8765/api/server/ready'],
+            ['file:///tmp/ready'],
+            ['not a url']
+        ])('rejects %s so the token never leaves the machine', (url) => {
+            const readiness = new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret', LTTH_READY_URL: url });
+            expect(readiness.url).toBeNull();
+            expect(readiness.enabled).toBe(false);
+        });
+
+        test('is disabled without a token or target (npm start)', () => {
+            expect(new LauncherReadiness({}).enabled).toBe(false);
+            expect(new LauncherReadiness({ LTTH_READY_URL: 'http://127.0.0.1:8765/api/server/ready' }).enabled).toBe(false);
+            expect(new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret' }).enabled).toBe(false);
+        });
+    });
+
+    describe('callback reports', () => {
+        let server;
+        let received;
+        let url;
+
+        beforeEach(async () => {
+            received = [];
+            server = http.createServer((req, res) => {
+                let body = '';
+                req.on('data', (chunk) => {
+                    body += chunk;
+                });
+                req.on('end', () => {
+                    received.push({ token: req.headers['x-launcher-token'], report: JSON.parse(body) });
+                    res.end();
+                });
+            });
+            await new Promise((resolve) => server.listen(0, '127.0.0.1', resolve));
+            url = `http://127.0.0.1:${server.address().port}/api/server/ready`;
+        });
+
+        afterEach(async () => {
+            if (server) {
+                await new Promise((resolve) => server.close(resolve));
+            }
+        });
+
+        test('sends every stage in order with the token', async () => {
+            const readiness = new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret', LTTH_READY_URL: url });
+
+            for (const stage of STAGES) {
+                await readiness.report(stage, stage === 'listening' ? { port: 3000 } : {});
+            }
+
+            expect(received.map((entry) => entry.report.stage)).toEqual(STAGES);
+            expect(received.every((entry) => entry.token === 'secret')).toBe(true);
+            // The port is remembered for the stages after "listening"
+            expect(received[4].report.port).toBe(3000);
+            expect(received[4].report.pid).toBe(process.pid);
+        });
+
+        test('ignores unknown stages', async () => {
+            const readiness = new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret', LTTH_READY_URL: url });
+
+            await readiness.report('almost-ready');
+
+            expect(received).toEqual([]);
+        });
+
+        test('resolves when the launcher is gone', async () => {
+            const readiness = new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret', LTTH_READY_URL: url });
+            await new Promise((resolve) => server.close(resolve));
+            server = null;
+
+            await expect(readiness.report('starting')).resolves.toBeUndefined();
+        });
+    });
+
+    describe('ready file', () => {
+        let tmpDir;
+
+        beforeEach(() => {
+            tmpDir = fs.mkdtempSync(path.join(os.tmpdir(), 'ltth-ready-'));
+        });
+
+        afterEach(() => {
+            fs.rmSync(tmpDir, { recursive: true, force: true });
+        });
+
+        test('holds the latest stage and the token', async () => {
+            const file = path.join(tmpDir, 'ready.json');
+            const readiness = new LauncherReadiness({ LTTH_LAUNCHER_TOKEN: 'secret', LTTH_READY_FILE: file });
+
+            await readiness.report('database');
+            await readiness.report('listening', { port: 3001 });
+            await readiness.report('ready');
+
+            const content = JSON.parse(fs.readFileSync(file, 'utf8'));
+            expect(content.stage).toBe('ready');
+            expect(content.port).toBe(3001);
+            expect(content.token).toBe('secret');
+            // Written via rename, no temporary file is left behind
+            expect(fs.existsSync(`${file}.tmp`)).toBe(false);
+        });
+    });
+});
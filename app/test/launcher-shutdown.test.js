@@ -0,0 +1,158 @@
+/**
+ * Test: Launcher Shutdown
+ *
+ * Verifies that POST /api/launcher/shutdown only accepts the launcher token from loopback
+ * addresses and that the graceful shutdown closes the database before the server exits.
+ */
+
+const {
+    isLoopbackAddress,
+    tokenMatches,
+    createShutdownRoute,
+    createGracefulShutdown
+} = require('../modules/launcher-shutdown');
+
+function mockRequest(remoteAddress, token) {
+    return {
+        socket: { remoteAddress },
+        get: (name) => (name === 'X-Launcher-Token' ? token : undefined)
+    };
+}
+
+function mockResponse() {
+    const res = { statusCode: 200, body: null };
+    res.status = jest.fn((code) => {
+        res.statusCode = code;
+        return res;
+    });
+    res.json = jest.fn((body) => {
+        res.body = body;
+        return res;
+    });
+    return res;
+}
+
+const nextTick = () => new Promise((resolve) => setImmediate(resolve));
+
+describe('Launcher Shutdown', () => {
+    describe('isLoopbackAddress', () => {
+        test('accepts IPv4, IPv6 and mapped loopback addresses', () => {
+            expect(isLoopbackAddress('127.0.0.1')).toBe(true);
+            expect(isLoopbackAddress('::1')).toBe(true);
+            expect(isLoopbackAddress('::ffff:127.0.0.1')).toBe(true);
+        });
+
+        test('rejects remote and missing addresses', () => {
+            expect(isLoopbackAddress('192.168.1.20')).toBe(false);
+            expect(isLoopbackAddress('::ffff:10.0.0.5')).toBe(false);
+            expect(isLoopbackAddress(undefined)).toBe(false);
+        });
+    });
+
+    describe('tokenMatches', () => {
+        test('requires a configured token', () => {
+            expect(tokenMatches('', '')).toBe(false);
+            expect(tokenMatches(undefined, 'secret')).toBe(false);
+        });
+
+        test('compares the full token', () => {
+            expect(tokenMatches('secret', 'secret')).toBe(true);
+            expect(tokenMatches('secret', 'secre')).toBe(false);
+            expect(tokenMatches('secret', undefined)).toBe(false);
+        });
+    });
+
+    describe('POST /api/launcher/shutdown', () => {
+        const env = { LTTH_LAUNCHER_TOKEN: 'secret' };
+
+        test('shuts down for the launcher token from loopback', async () => {
+            const shutdown = jest.fn();
+            const res = mockResponse();
+            createShutdownRoute(shutdown, env)(mockRequest('127.0.0.1', 'secret'), res);
+
+            expect(res.body).toEqual({ success: true });
+            // The response is sent before the server starts closing
+            expect(shutdown).not.toHaveBeenCalled();
+            await nextTick();
+            expect(shutdown).toHaveBeenCalledWith('launcher');
+        });
+
+        test.each([
+            ['a wrong token', '127.0.0.1', 'wrong', env],
+            ['a missing token', '127.0.0.1', undefined, env],
+            ['a remote address', '192.168.1.20', 'secret', env],
+            ['no launcher (npm start)', '127.0.0.1', '', {}]
+        ])('rejects %s', async (name, remoteAddress, token, routeEnv) => {
+            const shutdown = jest.fn();
+            const res = mockResponse();
+            createShutdownRoute(shutdown, routeEnv)(mockRequest(remoteAddress, token), res);
+
+            expect(res.statusCode).toBe(403);
+            await nextTick();
+            expect(shutdown).not.toHaveBeenCalled();
+        });
+    });
+
+    describe('gracefulShutdown', () => {
+        function createDeps(calls) {
+            return {
+                logger: { info: jest.fn(), error: jest.fn() },
+                tiktok: { isActive: () => true, disconnect: jest.fn(() => calls.push('tiktok')) },
+                obs: { isConnected: () => true, disconnect: jest.fn(async () => calls.push('obs')) },
+                cloudSync: { shutdown: jest.fn(async () => calls.push('cloudSync')) },
+                db: { close: jest.fn(() => calls.push('db')) },
+                server: {
+                    close: jest.fn((callback) => {
+                        calls.push('server');
+                        callback();
+                    })
+                },
+                exit: jest.fn((code) => calls.push(`exit:${code}`))
+            };
+        }
+
+        test('closes connections, database and server in order', async () => {
+            const calls = [];
+            await createGracefulShutdown(createDeps(calls))('SIGTERM');
+
+            expect(calls).toEqual(['tiktok', 'obs', 'cloudSync', 'db', 'server', 'exit:0']);
+        });
+
+        test('runs only once when launch.js forwards the signal again', async () => {
+            const calls = [];
+            const deps = createDeps(calls);
+            const gracefulShutdown = createGracefulShutdown(deps);
+
+            await Promise.all([gracefulShutdown('SIGINT'), gracefulShutdown('launcher')]);
+
+            expect(deps.db.close).toHaveBeenCalledTimes(1);
+            expect(deps.exit).toHaveBeenCalledTimes(1);
+        });
+
+        test('still closes the database when cloud sync fails', async () => {
+            const calls = [];
+            const deps = createDeps(calls);
+            deps.cloudSync.shutdown = jest.fn(async () => {
+                throw new Error('sync busy');
+            });
+
+            await createGracefulShutdown(deps)('launcher');
+
+            expect(deps.logger.error).toHaveBeenCalled();
+            expect(calls).toEqual(['tiktok', 'obs', 'db', 'server', 'exit:0']);
+        });
+
+        test('skips inactive connections', async () => {
+            const calls = [];
+            const deps = createDeps(calls);
+            deps.tiktok.isActive = () => false;
+            deps.obs.isConnected = () => false;
+
+            await createGracefulShutdown(deps)('SIGTERM');
+
+            expect(deps.tiktok.disconnect).not.toHaveBeenCalled();
+            expect(deps.obs.disconnect).not.toHaveBeenCalled();
+            expect(calls).toEqual(['cloudSync', 'db', 'server', 'exit:0']);
+        });
+    });
+});
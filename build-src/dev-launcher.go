@@ -6,6 +6,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
@@ -17,17 +18,20 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Loggableim/pupcidslittletiktokhelper/internal/events"
 	"github.com/pkg/browser"
 )
 
 type Launcher struct {
 	nodePath     string
 	appDir       string
+	mutex        sync.Mutex // Guards progress and status
 	progress     int
 	status       string
-	clients      map[chan string]bool
+	events       *events.Broker // Server-sent events to the launcher page
 	logFile      *os.File
 	logger       *log.Logger
 	envFileFixed bool // Track if we auto-created .env file
@@ -37,7 +41,7 @@ func NewLauncher() *Launcher {
 	return &Launcher{
 		status:       "Initialisiere...",
 		progress:     0,
-		clients:      make(map[chan string]bool),
+		events:       events.NewBroker(),
 		envFileFixed: false,
 	}
 }
@@ -107,26 +111,25 @@ func (l *Launcher) logAndSync(format string, args ...interface{}) {
 }
 
 func (l *Launcher) updateProgress(value int, status string) {
+	l.mutex.Lock()
 	l.progress = value
 	l.status = status
+	l.mutex.Unlock()
 
-	msg := fmt.Sprintf(`{"progress": %d, "status": "%s"}`, value, status)
-	for client := range l.clients {
-		select {
-		case client <- msg:
-		default:
-		}
-	}
+	msg, _ := json.Marshal(map[string]interface{}{"progress": value, "status": status})
+	l.events.Publish(events.Progress, string(msg))
+}
+
+// progressSnapshot returns the current progress and status, sent to newly connected pages
+func (l *Launcher) progressSnapshot() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	msg, _ := json.Marshal(map[string]interface{}{"progress": l.progress, "status": l.status})
+	return string(msg)
 }
 
 func (l *Launcher) sendRedirect() {
-	msg := `{"redirect": "http://localhost:3000/dashboard.html"}`
-	for client := range l.clients {
-		select {
-		case client <- msg:
-		default:
-		}
-	}
+	l.events.Publish(events.Progress, `{"redirect": "http://localhost:3000/dashboard.html"}`)
 }
 
 func (l *Launcher) checkNodeJS() error {
@@ -934,6 +937,15 @@ func main() {
             progressBar.textContent = data.progress + '%';
             statusText.textContent = data.status;
         };
+        
+        // Events are typed (progress, error, ...); named events skip onmessage
+        ['progress', 'prompt', 'preflight', 'error', 'log'].forEach(function(type) {
+            evtSource.addEventListener(type, function(event) {
+                if (event.data !== undefined) { // Connection errors are dispatched as 'error' too
+                    evtSource.onmessage(event);
+                }
+            });
+        });
     </script>
 </body>
 </html>
@@ -946,33 +958,7 @@ func main() {
 	})
 
 	http.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
-
-		client := make(chan string, 10)
-		launcher.clients[client] = true
-
-		// Send initial state
-		msg := fmt.Sprintf(`{"progress": %d, "status": "%s"}`, launcher.progress, launcher.status)
-		fmt.Fprintf(w, "data: %s\n\n", msg)
-		if f, ok := w.(http.Flusher); ok {
-			f.Flush()
-		}
-
-		// Listen for updates
-		for {
-			select {
-			case msg := <-client:
-				fmt.Fprintf(w, "data: %s\n\n", msg)
-				if f, ok := w.(http.Flusher); ok {
-					f.Flush()
-				}
-			case <-r.Context().Done():
-				delete(launcher.clients, client)
-				return
-			}
-		}
+		events.Serve(w, r, launcher.events, launcher.progressSnapshot)
 	})
 
 	// Start HTTP server
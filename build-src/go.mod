@@ -2,6 +2,7 @@ module github.com/Loggableim/pupcidslittletiktokhelper
 
 go 1.24.10
 
-require github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c
-
-require golang.org/x/sys v0.1.0 // indirect
+require (
+	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c
+	golang.org/x/sys v0.1.0
+)
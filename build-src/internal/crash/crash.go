@@ -0,0 +1,196 @@
+// Package crash recognises why the LTTH server stopped from its last stdout and stderr output
+// and names the one-click fix the launchers offer for it. It is shared by launcher.go and
+// launcher-gui.go, which keep the output tail of the running server for it.
+package crash
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Causes recognised in the last output of a crashed server
+const (
+	Unknown            = "unknown"
+	PortInUse          = "port_in_use"         // EADDRINUSE, Port is set
+	ModuleNotFound     = "module_not_found"    // MODULE_NOT_FOUND, Module is a package or a file
+	ModuleVersion      = "module_version"      // NODE_MODULE_VERSION mismatch of a native module
+	DatabaseCorrupt    = "database_corrupt"    // SQLITE_CORRUPT
+	DatabaseBusy       = "database_busy"       // SQLITE_BUSY, another process holds the database
+	ConfigJSON         = "config_json"         // JSON parse error, File is set if it was named
+	UnhandledRejection = "unhandled_rejection" // Plugin is set if the stack points into a plugin
+)
+
+// One-click fixes offered for a crash cause
+const (
+	FixSwitchPort          = "switch_port"
+	FixInstallDependencies = "install_dependencies"
+	FixRebuildModules      = "rebuild_modules"
+	FixMoveDatabase        = "move_database"
+	FixResetConfig         = "reset_config"
+	FixDisablePlugin       = "disable_plugin"
+)
+
+var (
+	addrInUsePattern      = regexp.MustCompile(`EADDRINUSE\b[^\n]*?:(\d{1,5})\b`)
+	moduleNotFoundPattern = regexp.MustCompile(`Cannot find module '([^']+)'`)
+	moduleVersionPattern  = regexp.MustCompile(`NODE_MODULE_VERSION \d+`)
+	nodeModulePattern     = regexp.MustCompile(`node_modules[\\/]((?:@[^\\/\s'"]+[\\/])?[^\\/\s'"]+)`)
+	sqliteCorruptPattern  = regexp.MustCompile(`SQLITE_CORRUPT|database disk image is malformed`)
+	sqliteBusyPattern     = regexp.MustCompile(`SQLITE_BUSY|database is locked`)
+	jsonErrorPattern      = regexp.MustCompile(`SyntaxError:[^\n]*(?:in JSON|is not valid JSON|JSON input)`)
+	jsonFilePattern       = regexp.MustCompile(`(?:[A-Za-z]:)?[^\s'"():]*[\\/][^\s'"():\\/]+\.json\b`)
+	databaseFilePattern   = regexp.MustCompile(`(?:[A-Za-z]:)?[^\s'"():]*[\\/][^\s'"():\\/]+\.db\b`)
+	rejectionPattern      = regexp.MustCompile(`(?i)unhandled ?(?:promise ?)?rejection`)
+	pluginPathPattern     = regexp.MustCompile(`[\\/]plugins[\\/]([^\\/\s'"():]+)[\\/]`)
+)
+
+// Analysis is the cause of a server crash, recognised in its last output
+type Analysis struct {
+	Cause   string `json:"cause"`            // One of the cause constants
+	Message string `json:"message"`          // Output line the cause was recognised in
+	Port    int    `json:"port,omitempty"`   // Port that was already in use
+	Module  string `json:"module,omitempty"` // Missing or mismatched module, a package name or a file
+	File    string `json:"file,omitempty"`   // Offending config or database file
+	Plugin  string `json:"plugin,omitempty"` // Plugin directory (app/plugins/<name>) the error came from
+	Fix     string `json:"fix,omitempty"`    // One-click fix for the cause, "" if there is none
+}
+
+// Classify recognises the cause of a crash in the combined stdout and stderr of the
+// server. Causes that stop the server reliably are checked first, unhandled rejections are
+// often only logged and come last.
+func Classify(output []byte) Analysis {
+	text := string(output)
+
+	if loc := addrInUsePattern.FindStringSubmatchIndex(text); loc != nil {
+		port, _ := strconv.Atoi(text[loc[2]:loc[3]])
+		return Analysis{Cause: PortInUse, Message: lineAt(text, loc[0]), Port: port, Fix: FixSwitchPort}
+	}
+
+	if loc := moduleVersionPattern.FindStringIndex(text); loc != nil {
+		analysis := Analysis{Cause: ModuleVersion, Message: lineAt(text, loc[0]), Fix: FixRebuildModules}
+		// "The module '.../node_modules/better-sqlite3/build/Release/better_sqlite3.node'" precedes the versions
+		start := loc[0] - 1024
+		if start < 0 {
+			start = 0
+		}
+		if modules := nodeModulePattern.FindAllStringSubmatch(text[start:loc[1]], -1); len(modules) > 0 {
+			analysis.Module = filepath.ToSlash(modules[len(modules)-1][1])
+		}
+		return analysis
+	}
+
+	if loc := moduleNotFoundPattern.FindStringSubmatchIndex(text); loc != nil {
+		analysis := Analysis{Cause: ModuleNotFound, Message: lineAt(text, loc[0]), Module: text[loc[2]:loc[3]]}
+		// The require stack follows the message and shows who required the module
+		analysis.Plugin = pluginAfter(text, loc[1])
+		switch {
+		case !IsFileModule(analysis.Module):
+			analysis.Fix = FixInstallDependencies
+		case analysis.Plugin != "":
+			analysis.Fix = FixDisablePlugin
+		}
+		return analysis
+	}
+
+	if loc := sqliteCorruptPattern.FindStringIndex(text); loc != nil {
+		analysis := Analysis{Cause: DatabaseCorrupt, Message: lineAt(text, loc[0])}
+		if files := databaseFilePattern.FindAllString(text, -1); len(files) > 0 {
+			analysis.File = files[len(files)-1]
+			analysis.Fix = FixMoveDatabase
+		}
+		return analysis
+	}
+
+	if loc := sqliteBusyPattern.FindStringIndex(text); loc != nil {
+		analysis := Analysis{Cause: DatabaseBusy, Message: lineAt(text, loc[0])}
+		if files := databaseFilePattern.FindAllString(text, -1); len(files) > 0 {
+			analysis.File = files[len(files)-1]
+		}
+		return analysis
+	}
+
+	if loc := jsonErrorPattern.FindStringIndex(text); loc != nil {
+		analysis := Analysis{Cause: ConfigJSON, Message: lineAt(text, loc[0])}
+		// require() names the file in the message, loaders often log it on the line before
+		lineStart := strings.LastIndex(text[:loc[0]], "\n") + 1
+		previousStart := 0
+		if lineStart > 0 {
+			previousStart = strings.LastIndex(text[:lineStart-1], "\n") + 1
+		}
+		if files := jsonFilePattern.FindAllString(text[previousStart:loc[1]], -1); len(files) > 0 {
+			analysis.File = files[len(files)-1]
+			// package.json is part of the installation, not a config with defaults
+			if name := strings.ToLower(analysis.File[strings.LastIndexAny(analysis.File, `\/`)+1:]); name != "package.json" && name != "package-lock.json" {
+				analysis.Fix = FixResetConfig
+			}
+		}
+		return analysis
+	}
+
+	if loc := rejectionPattern.FindStringIndex(text); loc != nil {
+		analysis := Analysis{Cause: UnhandledRejection, Message: lineAt(text, loc[0]), Plugin: pluginAfter(text, loc[1])}
+		if analysis.Plugin != "" {
+			analysis.Fix = FixDisablePlugin
+		}
+		return analysis
+	}
+
+	return Analysis{Cause: Unknown}
+}
+
+// lineAt returns the trimmed output line containing index
+func lineAt(text string, index int) string {
+	start := strings.LastIndex(text[:index], "\n") + 1
+	end := strings.Index(text[index:], "\n")
+	if end < 0 {
+		end = len(text)
+	} else {
+		end += index
+	}
+	return strings.TrimSpace(text[start:end])
+}
+
+// pluginAfter returns the first plugin directory in the stack trace following index
+func pluginAfter(text string, index int) string {
+	end := index + 2048
+	if end > len(text) {
+		end = len(text)
+	}
+	if match := pluginPathPattern.FindStringSubmatch(text[index:end]); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// IsFileModule reports whether a module passed to require() is a file rather than a package
+func IsFileModule(module string) bool {
+	return strings.HasPrefix(module, ".") || strings.HasPrefix(module, "/") || strings.HasPrefix(module, `\`) || filepath.IsAbs(module) || (len(module) > 1 && module[1] == ':')
+}
+
+// ResolveFile resolves a file named in the server output (Analysis.File) for a fix. Relative
+// paths are relative to appDir. The output is not trusted: paths outside the given roots,
+// also through symlinks, are refused.
+func ResolveFile(file, appDir string, roots ...string) (string, error) {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(appDir, file)
+	}
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", err
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolvedRoot
+		}
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside the LTTH directories", file)
+}
@@ -0,0 +1,137 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test the recognised causes and offered fixes for typical crash output
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Analysis
+	}{
+		{
+			name:   "port in use",
+			output: "Error: listen EADDRINUSE: address already in use :::3000\n    at Server.setupListenHandle [as _listen2] (node:net:1817:16)\n",
+			want:   Analysis{Cause: PortInUse, Message: "Error: listen EADDRINUSE: address already in use :::3000", Port: 3000, Fix: FixSwitchPort},
+		},
+		{
+			name:   "missing package",
+			output: "Error: Cannot find module 'express'\nRequire stack:\n- C:\\LTTH\\app\\server.js\n  code: 'MODULE_NOT_FOUND'\n",
+			want:   Analysis{Cause: ModuleNotFound, Message: "Error: Cannot find module 'express'", Module: "express", Fix: FixInstallDependencies},
+		},
+		{
+			name:   "missing plugin file",
+			output: "Error: Cannot find module './lib/engine'\nRequire stack:\n- /opt/ltth/app/plugins/soundboard/main.js\n- /opt/ltth/app/modules/plugin-loader.js\n",
+			want:   Analysis{Cause: ModuleNotFound, Message: "Error: Cannot find module './lib/engine'", Module: "./lib/engine", Plugin: "soundboard", Fix: FixDisablePlugin},
+		},
+		{
+			name: "native module version",
+			output: "Error: The module '/opt/ltth/app/node_modules/better-sqlite3/build/Release/better_sqlite3.node'\n" +
+				"was compiled against a different Node.js version using\nNODE_MODULE_VERSION 115. This version of Node.js requires\nNODE_MODULE_VERSION 127.\n",
+			want: Analysis{Cause: ModuleVersion, Message: "NODE_MODULE_VERSION 115. This version of Node.js requires", Module: "better-sqlite3", Fix: FixRebuildModules},
+		},
+		{
+			name:   "corrupt database",
+			output: "Opening database C:\\Users\\me\\ltth\\user_configs\\streamer.db\nSqliteError: database disk image is malformed\n  code: 'SQLITE_CORRUPT'\n",
+			want:   Analysis{Cause: DatabaseCorrupt, Message: "SqliteError: database disk image is malformed", File: "C:\\Users\\me\\ltth\\user_configs\\streamer.db", Fix: FixMoveDatabase},
+		},
+		{
+			name:   "busy database",
+			output: "SqliteError: database is locked\n  code: 'SQLITE_BUSY'\n",
+			want:   Analysis{Cause: DatabaseBusy, Message: "SqliteError: database is locked"},
+		},
+		{
+			name:   "broken config",
+			output: "SyntaxError: /opt/ltth/app/user_configs/settings.json: Unexpected token } in JSON at position 42\n",
+			want:   Analysis{Cause: ConfigJSON, Message: "SyntaxError: /opt/ltth/app/user_configs/settings.json: Unexpected token } in JSON at position 42", File: "/opt/ltth/app/user_configs/settings.json", Fix: FixResetConfig},
+		},
+		{
+			name:   "broken package.json",
+			output: "Error loading /opt/ltth/app/package.json\nSyntaxError: Unexpected end of JSON input\n",
+			want:   Analysis{Cause: ConfigJSON, Message: "SyntaxError: Unexpected end of JSON input", File: "/opt/ltth/app/package.json"},
+		},
+		{
+			name:   "plugin rejection",
+			output: "❌ Unhandled Rejection at: Promise reason: TypeError: Cannot read properties of undefined\n    at Timeout._onTimeout (C:\\LTTH\\app\\plugins\\weather-control\\main.js:88:21)\n",
+			want:   Analysis{Cause: UnhandledRejection, Message: "❌ Unhandled Rejection at: Promise reason: TypeError: Cannot read properties of undefined", Plugin: "weather-control", Fix: FixDisablePlugin},
+		},
+		{
+			name:   "unknown",
+			output: "ReferenceError: foo is not defined\n",
+			want:   Analysis{Cause: Unknown},
+		},
+	}
+
+	for _, test := range tests {
+		if got := Classify([]byte(test.output)); got != test.want {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.want, got)
+		}
+	}
+}
+
+// Test that relative and absolute paths are told apart from package names
+func TestIsFileModule(t *testing.T) {
+	for _, module := range []string{"./lib/engine", "../config", "/opt/ltth/app/server.js", `C:\LTTH\app\server.js`, `\\server\share\x.js`} {
+		if !IsFileModule(module) {
+			t.Errorf("Expected %s to be a file", module)
+		}
+	}
+	for _, module := range []string{"express", "@napi-rs/canvas", "better-sqlite3/build/Release/better_sqlite3.node"} {
+		if IsFileModule(module) {
+			t.Errorf("Expected %s to be a package", module)
+		}
+	}
+}
+
+// Test that fixes only touch files inside the app and user_configs directories
+func TestResolveFile(t *testing.T) {
+	base := t.TempDir()
+	appDir := filepath.Join(base, "app")
+	userConfigsDir := filepath.Join(base, "config", "user_configs")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(appDir, "user_configs"), userConfigsDir, outside} {
+		os.MkdirAll(dir, 0755)
+	}
+	settings := filepath.Join(appDir, "user_configs", "settings.json")
+	database := filepath.Join(userConfigsDir, "streamer.db")
+	secret := filepath.Join(outside, "secret.json")
+	for _, file := range []string{settings, database, secret} {
+		os.WriteFile(file, []byte("{}"), 0644)
+	}
+
+	allowed := map[string]string{
+		settings:                     settings,
+		"user_configs/settings.json": settings,
+		database:                     database,
+	}
+	for file, want := range allowed {
+		got, err := ResolveFile(file, appDir, appDir, userConfigsDir)
+		if err != nil {
+			t.Errorf("Expected %s to be allowed, got %v", file, err)
+			continue
+		}
+		if wantResolved, _ := filepath.EvalSymlinks(want); got != wantResolved {
+			t.Errorf("Expected %s to resolve to %s, got %s", file, wantResolved, got)
+		}
+	}
+
+	refused := []string{
+		secret,
+		"../outside/secret.json",
+		filepath.Join(appDir, "..", "outside", "secret.json"),
+		appDir,
+		filepath.Join(appDir, "missing.json"),
+	}
+	if err := os.Symlink(secret, filepath.Join(appDir, "link.json")); err == nil {
+		refused = append(refused, filepath.Join(appDir, "link.json"))
+	}
+	for _, file := range refused {
+		if got, err := ResolveFile(file, appDir, appDir, userConfigsDir); err == nil {
+			t.Errorf("Expected %s to be refused, got %s", file, got)
+		}
+	}
+}
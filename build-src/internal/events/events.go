@@ -0,0 +1,153 @@
+// Package events streams launcher messages to the browser pages of the launchers as
+// server-sent events. Every event is numbered, so a page that reconnects after standby or a
+// dropped connection sends its Last-Event-ID and gets the events it missed replayed.
+package events
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event types of the launcher page streams. The payload keeps its "type" field for the page,
+// the event type groups payloads for listeners and replay.
+const (
+	Progress  = "progress"  // Progress, status, startup stages and redirects
+	Prompt    = "prompt"    // Decisions the user has to make
+	Preflight = "preflight" // System check results and automatic fixes
+	Error     = "error"     // Errors and crashes
+	Log       = "log"       // Informational messages: warnings, statistics, restarts
+)
+
+const (
+	historySize       = 256              // Events kept for Last-Event-ID replay
+	bufferSize        = 64               // Events queued per page before it is disconnected
+	keepAliveInterval = 15 * time.Second // Comment sent on idle streams, so proxies and browsers keep them open
+)
+
+// Event is one server-sent event with its JSON payload
+type Event struct {
+	ID   uint64
+	Type string
+	Data string
+}
+
+// Broker fans out events to all connected pages. Events are numbered monotonically and
+// the latest are kept, so a reconnecting page gets the events it missed.
+type Broker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Event
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker returns a broker without events and pages
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]struct{})}
+}
+
+// Publish numbers an event, records it and queues it for every page. A page that does not keep
+// up is disconnected instead of blocking the launcher; it reconnects and gets the event replayed.
+func (b *Broker) Publish(eventType, data string) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Data: data}
+	b.history = append(b.history, event)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a page that received all events up to lastID (0 for a new page). It
+// returns the recorded events after lastID and whether they are complete; events published
+// later arrive on the channel until unsubscribe is called or the page falls behind.
+func (b *Broker) Subscribe(lastID uint64) (missed []Event, complete bool, events <-chan Event, unsubscribe func()) {
+	subscriber := make(chan Event, bufferSize)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// An ID beyond the last event was issued by an earlier launcher run
+	if lastID > 0 && lastID <= b.lastID {
+		complete = len(b.history) == 0 || b.history[0].ID <= lastID+1
+		for _, event := range b.history {
+			if event.ID > lastID {
+				missed = append(missed, event)
+			}
+		}
+	}
+	b.subscribers[subscriber] = struct{}{}
+	return missed, complete, subscriber, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[subscriber]; ok {
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Serve streams the events of broker to one page. Browsers reconnect on their own and send
+// the last received ID as Last-Event-ID (pages that reconnect themselves pass ?lastEventId=);
+// missed events are replayed. New pages, and pages that missed more than the history holds,
+// start with the current state from snapshot.
+func Serve(w http.ResponseWriter, r *http.Request, broker *Broker, snapshot func() string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	lastID, _ := strconv.ParseUint(lastEventID, 10, 64)
+	missed, complete, events, unsubscribe := broker.Subscribe(lastID)
+	defer unsubscribe()
+
+	if !complete {
+		// Without an ID, so the browser keeps its Last-Event-ID
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", Progress, snapshot())
+	}
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return // Fell behind, the browser reconnects with its Last-Event-ID
+			}
+			writeEvent(w, event)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one event in the text/event-stream format
+func writeEvent(w io.Writer, event Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
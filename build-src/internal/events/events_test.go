@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Test numbering, replay and disconnecting of pages that fall behind
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+	first := broker.Publish(Progress, `{"progress":10}`)
+	second := broker.Publish(Error, `{"error":"x"}`)
+	if first.ID != 1 || second.ID != 2 {
+		t.Fatalf("Expected IDs 1 and 2, got %d and %d", first.ID, second.ID)
+	}
+
+	missed, complete, events, unsubscribe := broker.Subscribe(1)
+	if !complete || len(missed) != 1 || missed[0] != second {
+		t.Fatalf("Expected replay of event 2, got %+v (complete %v)", missed, complete)
+	}
+	broker.Publish(Log, `{"type":"server-stats"}`)
+	if event := <-events; event.ID != 3 || event.Type != Log {
+		t.Errorf("Expected event 3 of type log, got %+v", event)
+	}
+	unsubscribe()
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("Channel should be closed after unsubscribe")
+	}
+
+	// New pages and IDs of an earlier run get the snapshot instead of a replay
+	for _, lastID := range []uint64{0, 99} {
+		missed, complete, _, unsubscribe := broker.Subscribe(lastID)
+		unsubscribe()
+		if complete || len(missed) != 0 {
+			t.Errorf("Subscribe(%d): expected no replay, got %+v (complete %v)", lastID, missed, complete)
+		}
+	}
+
+	for i := 0; i < historySize; i++ {
+		broker.Publish(Progress, "{}")
+	}
+	missed, complete, _, unsubscribe = broker.Subscribe(1)
+	unsubscribe()
+	if complete || len(missed) != historySize {
+		t.Errorf("Expected incomplete replay of %d events, got %d (complete %v)", historySize, len(missed), complete)
+	}
+
+	// A page that falls behind is disconnected instead of blocking Publish
+	_, _, slow, unsubscribe := broker.Subscribe(0)
+	defer unsubscribe()
+	for i := 0; i <= bufferSize; i++ {
+		broker.Publish(Progress, "{}")
+	}
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != bufferSize {
+		t.Errorf("Expected %d buffered events before disconnect, got %d", bufferSize, received)
+	}
+}
+
+// Test that concurrent publishers and subscribers see monotonic IDs
+func TestBrokerConcurrent(t *testing.T) {
+	broker := NewBroker()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				broker.Publish(Progress, "{}")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				missed, _, _, unsubscribe := broker.Subscribe(uint64(j))
+				for k := 1; k < len(missed); k++ {
+					if missed[k].ID != missed[k-1].ID+1 {
+						t.Errorf("Replay not monotonic: %d after %d", missed[k].ID, missed[k-1].ID)
+					}
+				}
+				unsubscribe()
+			}
+		}()
+	}
+	wg.Wait()
+	if event := broker.Publish(Progress, "{}"); event.ID != 401 {
+		t.Errorf("Expected ID 401, got %d", event.ID)
+	}
+}
+
+// Test the replay after Last-Event-ID and the snapshot for new pages
+func TestServe(t *testing.T) {
+	broker := NewBroker()
+	broker.Publish(Progress, `{"progress":10}`)
+	broker.Publish(Progress, `{"progress":20}`)
+	snapshot := func() string { return `{"progress":20,"status":"Node.js"}` }
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   string
+	}{
+		{name: "Last-Event-ID header", header: "1", want: "id: 2\nevent: progress\ndata: {\"progress\":20}\n\n"},
+		{name: "lastEventId parameter", query: "?lastEventId=1", want: "id: 2\nevent: progress\ndata: {\"progress\":20}\n\n"},
+		{name: "new page", want: "event: progress\ndata: {\"progress\":20,\"status\":\"Node.js\"}\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The page is gone right after the replay
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest(http.MethodGet, "/events"+tt.query, nil).WithContext(ctx)
+			if tt.header != "" {
+				req.Header.Set("Last-Event-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			Serve(rec, req, broker, snapshot)
+
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("Serve() wrote %q, want %q", got, tt.want)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package instancelock keeps two launchers from using the same installation at the same time.
+// The lock file records PID, start time and URL of its owner, but ownership is decided by an
+// operating system lock (flock on Unix, LockFileEx on Windows) held while the owner runs. The
+// OS drops it when the process ends, so a crashed run, a reboot or a reused PID never block.
+package instancelock
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrRunning is returned by Acquire while another launcher holds the lock
+var ErrRunning = errors.New("another launcher is running")
+
+// errLocked is returned by lockFile if another process holds the lock
+var errLocked = errors.New("lock file is locked")
+
+// maxInfoSize limits how much of the lock file is read
+const maxInfoSize = 64 * 1024
+
+// Info is the content of the lock file
+type Info struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	URL     string    `json:"url"` // Opened by a second start
+}
+
+// Lock is an acquired instance lock
+type Lock struct {
+	file *os.File
+}
+
+// Acquire locks the file at path for this process and records url in it. If another launcher
+// holds the lock, its Info (nil while it is still writing it) and ErrRunning are returned.
+// The Info of a launcher that ended without releasing the lock is returned with the lock.
+func Acquire(path, url string) (*Lock, *Info, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := lockFile(file); err != nil {
+		holder := readInfo(file)
+		file.Close()
+		if errors.Is(err, errLocked) {
+			return nil, holder, ErrRunning
+		}
+		return nil, nil, err
+	}
+
+	stale := readInfo(file)
+	data, _ := json.Marshal(Info{PID: os.Getpid(), Started: time.Now(), URL: url})
+	if err := file.Truncate(0); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, nil, err
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, nil, err
+	}
+	return &Lock{file: file}, stale, nil
+}
+
+// Release clears the lock file and drops the OS lock. The file itself stays in place:
+// removing it could delete the lock another launcher acquired in the meantime.
+func (l *Lock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+	l.file.Truncate(0)
+	unlockFile(l.file)
+	l.file.Close()
+	l.file = nil
+}
+
+// readInfo returns the recorded owner, or nil for an empty or unreadable lock file
+func readInfo(file *os.File) *Info {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, maxInfoSize))
+	if err != nil || len(content) == 0 {
+		return nil
+	}
+	info := &Info{}
+	if json.Unmarshal(content, info) != nil {
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,90 @@
+package instancelock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test that a held lock blocks a second launcher and is free again after Release
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launcher.lock")
+	lock, stale, err := Acquire(path, "http://localhost:3000/dashboard.html")
+	if err != nil || stale != nil {
+		t.Fatalf("Expected a fresh lock, got %+v (%v)", stale, err)
+	}
+
+	// The second launcher learns who holds the lock and what to open
+	_, holder, err := Acquire(path, "")
+	if err != ErrRunning || holder == nil || holder.PID != os.Getpid() || holder.URL != "http://localhost:3000/dashboard.html" {
+		t.Errorf("Expected ErrRunning with holder info, got %+v (%v)", holder, err)
+	}
+
+	lock.Release()
+	if content, err := os.ReadFile(path); err != nil || len(content) != 0 {
+		t.Errorf("Expected an empty lock file after release, got %q (%v)", content, err)
+	}
+	second, stale, err := Acquire(path, "")
+	if err != nil || stale != nil {
+		t.Fatalf("Expected the released lock to be free, got %+v (%v)", stale, err)
+	}
+	second.Release()
+}
+
+// Test that a lock file left behind by a crash or reboot does not block, even if its PID
+// now belongs to another live process
+func TestAcquireStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launcher.lock")
+	data, _ := json.Marshal(Info{PID: os.Getppid(), URL: "http://localhost:3000/dashboard.html"})
+	os.WriteFile(path, data, 0644)
+
+	lock, stale, err := Acquire(path, "")
+	if err != nil || stale == nil || stale.PID != os.Getppid() {
+		t.Fatalf("Expected the stale lock of PID %d to be recovered, got %+v (%v)", os.Getppid(), stale, err)
+	}
+	defer lock.Release()
+
+	var info Info
+	if content, err := os.ReadFile(path); err != nil || json.Unmarshal(content, &info) != nil || info.PID != os.Getpid() {
+		t.Errorf("Expected the lock file to name this process, got %+v (%v)", info, err)
+	}
+}
+
+// Test that only a launcher answering with its app ID counts as running
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{name: "launcher", status: http.StatusOK, body: `{"app":"ltth-launcher-gui","pid":42,"url":"http://localhost:3000/dashboard.html"}`, want: true},
+		{name: "other app on the port", status: http.StatusOK, body: `{"app":"something-else"}`},
+		{name: "no JSON", status: http.StatusOK, body: "<html>"},
+		{name: "error status", status: http.StatusNotFound, body: `{"app":"ltth-launcher-gui"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			info, ok := Probe(server.URL)
+			if ok != tt.want {
+				t.Fatalf("Probe() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && (info.PID != 42 || info.URL != "http://localhost:3000/dashboard.html") {
+				t.Errorf("Unexpected instance %+v", info)
+			}
+		})
+	}
+
+	if _, ok := Probe("http://127.0.0.1:1/api/instance"); ok {
+		t.Error("Expected no instance without a listening launcher")
+	}
+}
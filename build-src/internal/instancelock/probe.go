@@ -0,0 +1,37 @@
+package instancelock
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// The lock file in the installation directory and the loopback probe of launcher-gui.exe
+const (
+	FileName = "launcher.lock"
+	ProbeURL = "http://127.0.0.1:58734/api/instance"
+	AppID    = "ltth-launcher-gui"
+)
+
+// Instance is the answer of a running launcher to the loopback probe
+type Instance struct {
+	App string `json:"app"`
+	PID int    `json:"pid"`
+	URL string `json:"url"` // Dashboard while the server is ready, the launcher UI otherwise
+}
+
+// Probe asks a launcher listening on url whether it is running and what to open
+func Probe(url string) (*Instance, bool) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	var info Instance
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&info) != nil || info.App != AppID {
+		return nil, false
+	}
+	return &info, true
+}
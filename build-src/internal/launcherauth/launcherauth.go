@@ -0,0 +1,53 @@
+// Package launcherauth protects the local launcher endpoints. Every launcher session creates a
+// random token, hands it to the server via LTTH_LAUNCHER_TOKEN and embeds it in its own UI.
+// Requests that change state must carry it and reach the launcher under a loopback host name,
+// so neither other web pages in the browser nor DNS rebinding can start, stop or quit anything.
+package launcherauth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries the token in requests from the launcher UI and the server
+const Header = "X-Launcher-Token"
+
+// NewToken returns a random token that authorizes the launcher UI and the server
+func NewToken() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// IsLocalRequest reports whether the request was addressed to a loopback host name
+func IsLocalRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Require only passes local requests that carry token in the X-Launcher-Token header
+func Require(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sent := r.Header.Get(Header)
+		if token == "" || !IsLocalRequest(r) || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,70 @@
+package launcherauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Test that only local requests with the session token reach the handler
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name  string
+		host  string
+		token string
+		want  int
+	}{
+		{name: "token on loopback", host: "127.0.0.1:58734", token: "secret", want: http.StatusOK},
+		{name: "token on localhost", host: "localhost:58734", token: "secret", want: http.StatusOK},
+		{name: "token on IPv6 loopback", host: "[::1]:58734", token: "secret", want: http.StatusOK},
+		{name: "missing token", host: "127.0.0.1:58734", want: http.StatusForbidden},
+		{name: "wrong token", host: "127.0.0.1:58734", token: "guess", want: http.StatusForbidden},
+		{name: "rebound host name", host: "attacker.example:58734", token: "secret", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := Require("secret", func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "http://"+tt.host+"/api/quit", nil)
+			if tt.token != "" {
+				req.Header.Set(Header, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+// Test that an empty session token never authorizes a request without header
+func TestRequireEmptyToken(t *testing.T) {
+	handler := Require("", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called without token")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:58734/api/quit", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+// Test that every session gets a different token
+func TestNewToken(t *testing.T) {
+	a, b := NewToken(), NewToken()
+	if len(a) != 32 || a == b {
+		t.Errorf("NewToken() = %q, %q", a, b)
+	}
+}
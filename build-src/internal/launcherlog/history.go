@@ -0,0 +1,56 @@
+package launcherlog
+
+import (
+	"strings"
+	"sync"
+)
+
+// Server output kept for crash reports and the crash analysis
+const (
+	StderrTailBytes = 16 * 1024
+	StderrTailLines = 20
+	ServerTailBytes = 64 * 1024 // Combined stdout and stderr
+)
+
+// History keeps the most recent output of the server, e.g. its stderr for crash reports
+type History struct {
+	mu   sync.Mutex
+	data []byte
+	max  int
+}
+
+// NewHistory returns a History that keeps the last max bytes
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+func (h *History) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.data = append(h.data, p...)
+	if len(h.data) > h.max {
+		h.data = append([]byte(nil), h.data[len(h.data)-h.max:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the recorded output
+func (h *History) Bytes() []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]byte(nil), h.data...)
+}
+
+// TailLines returns the last n non-empty lines of output
+func TailLines(output []byte, n int) []string {
+	lines := []string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,33 @@
+package launcherlog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// Test that only the most recent output is kept
+func TestHistory(t *testing.T) {
+	history := NewHistory(8)
+	fmt.Fprint(history, "first\n")
+	fmt.Fprint(history, "second\n")
+	if got := string(history.Bytes()); got != "\nsecond\n" {
+		t.Errorf("Bytes() = %q, want the last 8 bytes", got)
+	}
+
+	copied := history.Bytes()
+	copied[0] = 'x'
+	if got := string(history.Bytes()); got != "\nsecond\n" {
+		t.Errorf("Bytes() must return a copy, got %q", got)
+	}
+}
+
+// Test the tail of the server output shown after a crash
+func TestTailLines(t *testing.T) {
+	if lines := TailLines([]byte("a\r\n\n  \nb\nc\n"), 2); !reflect.DeepEqual(lines, []string{"b", "c"}) {
+		t.Errorf("Unexpected tail: %q", lines)
+	}
+	if lines := TailLines([]byte("a\r\n"), 5); !reflect.DeepEqual(lines, []string{"a"}) {
+		t.Errorf("Unexpected tail: %q", lines)
+	}
+}
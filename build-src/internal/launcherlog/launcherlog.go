@@ -0,0 +1,261 @@
+// Package launcherlog writes the launcher log: rotating <prefix>_<timestamp>.log files with a
+// size, age and retention limit from the "logging" section of launcher-settings.json, either as
+// plain text or as one JSON object per line. It also keeps the tail of the server output for
+// crash reports.
+package launcherlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Launcher log defaults, overridable in the "logging" section of launcher-settings.json
+const (
+	defaultLogMaxSizeMB   = 10
+	defaultLogMaxAgeHours = 24
+	defaultLogMaxFiles    = 10
+	defaultLogMaxDays     = 14
+	logTimestampFormat    = "2006-01-02_15-04-05" // Sortable, newer files sort last
+)
+
+// Settings configures rotation, retention and format of the launcher logs.
+// Read from the "logging" section of launcher-settings.json next to the executable.
+type Settings struct {
+	Format      string `json:"format,omitempty"`        // "text" (default) or "json": one JSON object per line
+	MaxSizeMB   int    `json:"max_size_mb,omitempty"`   // Start a new file when the current one reaches this size
+	MaxAgeHours int    `json:"max_age_hours,omitempty"` // Start a new file when the current one is this old
+	MaxFiles    int    `json:"max_files,omitempty"`     // Launcher logs kept, older ones are deleted
+	MaxDays     int    `json:"max_days,omitempty"`      // Launcher logs older than this are deleted
+}
+
+// WithDefaults fills unset values with the defaults
+func (s Settings) WithDefaults() Settings {
+	if s.Format != "json" {
+		s.Format = "text"
+	}
+	if s.MaxSizeMB <= 0 {
+		s.MaxSizeMB = defaultLogMaxSizeMB
+	}
+	if s.MaxAgeHours <= 0 {
+		s.MaxAgeHours = defaultLogMaxAgeHours
+	}
+	if s.MaxFiles <= 0 {
+		s.MaxFiles = defaultLogMaxFiles
+	}
+	if s.MaxDays <= 0 {
+		s.MaxDays = defaultLogMaxDays
+	}
+	return s
+}
+
+// LoadSettings reads the "logging" section of launcher-settings.json. Logging is set up
+// before anything else, so a missing or broken file only means the defaults.
+func LoadSettings(settingsPath string) Settings {
+	var settings struct {
+		Logging Settings `json:"logging"`
+	}
+	if data, err := os.ReadFile(settingsPath); err == nil {
+		json.Unmarshal(data, &settings)
+	}
+	return settings.Logging.WithDefaults()
+}
+
+// RotatingFile writes to <prefix>_<timestamp>.log in dir and starts a new file when the current
+// one exceeds the size or age limit. Every new file deletes old ones beyond the retention.
+type RotatingFile struct {
+	mu       sync.Mutex
+	dir      string
+	prefix   string
+	settings Settings
+	file     *os.File
+	size     int64
+	opened   time.Time
+}
+
+// OpenRotating creates dir and the first log file
+func OpenRotating(dir, prefix string, settings Settings) (*RotatingFile, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	r := &RotatingFile{dir: dir, prefix: prefix, settings: settings.WithDefaults()}
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	tooBig := r.size+int64(len(p)) > int64(r.settings.MaxSizeMB)<<20
+	tooOld := time.Since(r.opened) > time.Duration(r.settings.MaxAgeHours)*time.Hour
+	if r.size > 0 && (tooBig || tooOld) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate switches to a new file and applies the retention. The caller holds mu.
+func (r *RotatingFile) rotate() error {
+	now := time.Now()
+	name := fmt.Sprintf("%s_%s", r.prefix, now.Format(logTimestampFormat))
+	path := filepath.Join(r.dir, name+".log")
+	// Open with sync flag to ensure writes are flushed immediately. A second file within the
+	// same second gets a counter, which still sorts after the first one.
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND|os.O_SYNC, 0644)
+	for i := 2; os.IsExist(err) && i < 100; i++ {
+		path = filepath.Join(r.dir, fmt.Sprintf("%s_%02d.log", name, i))
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND|os.O_SYNC, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.file != nil {
+		r.file.Close()
+	}
+	r.file, r.size, r.opened = file, 0, now
+	prune(r.dir, r.prefix, r.settings, path)
+	return nil
+}
+
+// Name returns the path of the current file
+func (r *RotatingFile) Name() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return ""
+	}
+	return r.file.Name()
+}
+
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	return r.file.Sync()
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// prune deletes <prefix>_*.log files in dir that exceed MaxFiles (newest are kept) or are
+// older than MaxDays. current is never deleted.
+func prune(dir, prefix string, settings Settings, current string) {
+	logs, _ := filepath.Glob(filepath.Join(dir, prefix+"_*.log"))
+	sort.Sort(sort.Reverse(sort.StringSlice(logs)))
+	cutoff := time.Now().AddDate(0, 0, -settings.MaxDays)
+	kept := 1 // current
+	for _, path := range logs {
+		if path == current {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if kept < settings.MaxFiles && info.ModTime().After(cutoff) {
+			kept++
+			continue
+		}
+		os.Remove(path)
+	}
+}
+
+// Entry is one line of a launcher log in the JSON format
+type Entry struct {
+	Time    string `json:"time"`   // RFC 3339 with milliseconds
+	Level   string `json:"level"`  // "debug", "info", "warning" or "error"
+	Phase   string `json:"phase"`  // Phase of the launcher when the line was written
+	Source  string `json:"source"` // "launcher" or "server"
+	Message string `json:"message"`
+}
+
+var (
+	ansiPattern     = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+	logLevelPattern = regexp.MustCompile(`(?i)\[(debug|verbose|info|success|warn|warning|error|fatal)\]`)
+)
+
+// JSONLineWriter turns every line of output into an Entry. It expects whole lines, as
+// written by log.Logger and the redacting writers of the launchers.
+type JSONLineWriter struct {
+	w      io.Writer
+	source string
+	level  string        // Level of lines without a recognisable level
+	phase  func() string // Current phase, called for every line
+}
+
+// NewJSONLineWriter writes the output of source to w. Lines without a recognisable level get
+// level, e.g. "error" for the error output of the server; phase is called for every write.
+func NewJSONLineWriter(w io.Writer, source, level string, phase func() string) *JSONLineWriter {
+	return &JSONLineWriter{w: w, source: source, level: level, phase: phase}
+}
+
+func (jw *JSONLineWriter) Write(p []byte) (int, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	now := time.Now().Format("2006-01-02T15:04:05.000Z07:00")
+	phase := jw.phase()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\r\n"), "\n") {
+		line = strings.TrimRight(ansiPattern.ReplaceAllString(line, ""), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		encoder.Encode(Entry{Time: now, Level: Level(line, jw.level), Phase: phase, Source: jw.source, Message: line})
+	}
+	if _, err := jw.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Level returns the level of a log line: "[WARNING]" of the launcher, "[warn]:" of the
+// server logger, fallback otherwise
+func Level(line, fallback string) string {
+	// The level is near the start, after the timestamp of the server logger
+	head := line
+	if len(head) > 48 {
+		head = head[:48]
+	}
+	match := logLevelPattern.FindStringSubmatch(head)
+	if match == nil {
+		return fallback
+	}
+	switch strings.ToLower(match[1]) {
+	case "debug", "verbose":
+		return "debug"
+	case "warn", "warning":
+		return "warning"
+	case "error", "fatal":
+		return "error"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,137 @@
+package launcherlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test the defaults and the "logging" section of launcher-settings.json
+func TestLoadSettings(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "launcher-settings.json")
+
+	defaults := Settings{Format: "text", MaxSizeMB: 10, MaxAgeHours: 24, MaxFiles: 10, MaxDays: 14}
+	if got := LoadSettings(settingsPath); got != defaults {
+		t.Errorf("LoadSettings() without file = %+v, want %+v", got, defaults)
+	}
+
+	os.WriteFile(settingsPath, []byte(`{"logging": {"format": "json", "max_files": 3, "max_days": -1}}`), 0644)
+	want := Settings{Format: "json", MaxSizeMB: 10, MaxAgeHours: 24, MaxFiles: 3, MaxDays: 14}
+	if got := LoadSettings(settingsPath); got != want {
+		t.Errorf("LoadSettings() = %+v, want %+v", got, want)
+	}
+
+	os.WriteFile(settingsPath, []byte(`{broken`), 0644)
+	if got := LoadSettings(settingsPath); got != defaults {
+		t.Errorf("LoadSettings() with broken file = %+v, want %+v", got, defaults)
+	}
+}
+
+// Test size-based rotation of the launcher log
+func TestOpenRotating(t *testing.T) {
+	dir := t.TempDir()
+	rotating, err := OpenRotating(dir, "launcher", Settings{MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("OpenRotating() error = %v", err)
+	}
+	defer rotating.Close()
+	first := rotating.Name()
+
+	chunk := bytes.Repeat([]byte("x"), 600<<10)
+	rotating.Write(chunk)
+	if rotating.Name() != first {
+		t.Fatal("Log rotated before reaching the size limit")
+	}
+	rotating.Write(chunk)
+	if rotating.Name() == first {
+		t.Fatal("Expected a new file after exceeding 1 MB")
+	}
+
+	logs, _ := filepath.Glob(filepath.Join(dir, "launcher_*.log")) // Sorted by name
+	if len(logs) != 2 || logs[0] != first || logs[1] != rotating.Name() {
+		t.Errorf("Expected the new file to sort after %s, got %v", first, logs)
+	}
+}
+
+// Test the retention of old log files
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"launcher_2024-01-01_10-00-00.log",
+		"launcher_2024-01-02_10-00-00.log",
+		"launcher_2024-01-03_10-00-00.log",
+		"launcher_2024-01-04_10-00-00.log",
+		"server_2024-01-01.log", // Other logs are left alone
+	}
+	for _, name := range names {
+		os.WriteFile(filepath.Join(dir, name), []byte("log"), 0644)
+	}
+	old := time.Now().AddDate(0, 0, -30)
+	os.Chtimes(filepath.Join(dir, names[2]), old, old)
+
+	current := filepath.Join(dir, names[0]) // Never deleted, even though it sorts oldest
+	prune(dir, "launcher", Settings{MaxFiles: 2, MaxDays: 14}, current)
+
+	for i, want := range []bool{true, false, false, true, true} {
+		_, err := os.Stat(filepath.Join(dir, names[i]))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s: exists = %v, want %v", names[i], exists, want)
+		}
+	}
+}
+
+// Test the JSON-lines log format and the level of lines without level
+func TestJSONLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	phase := func() string { return "plugins" }
+	fmt.Fprint(NewJSONLineWriter(&out, "server", "info", phase), "2024-01-01 10:00:00 [\x1b[33mwarn\x1b[39m]: Plugin slow\n\nplain <line>\n")
+	fmt.Fprint(NewJSONLineWriter(&out, "server", "error", phase), "TypeError: x is not a function\n")
+
+	var entries []Entry
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var entry Entry
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("Invalid JSON line: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries (empty line skipped), got %d", len(entries))
+	}
+	if entries[0].Level != "warning" || entries[0].Message != "2024-01-01 10:00:00 [warn]: Plugin slow" {
+		t.Errorf("Unexpected first entry %+v", entries[0])
+	}
+	if entries[1].Level != "info" || entries[1].Source != "server" || entries[1].Phase != "plugins" || entries[1].Message != "plain <line>" {
+		t.Errorf("Unexpected second entry %+v", entries[1])
+	}
+	if entries[2].Level != "error" {
+		t.Errorf("Error output without level logged as %q, want error", entries[2].Level)
+	}
+	if _, err := time.Parse(time.RFC3339, entries[0].Time); err != nil {
+		t.Errorf("Time %q is not RFC 3339: %v", entries[0].Time, err)
+	}
+}
+
+// Test the level recognised in launcher and server log lines
+func TestLevel(t *testing.T) {
+	for line, want := range map[string]string{
+		"2024/01/01 10:00:00 [ERROR] npm failed":    "error",
+		"2024/01/01 10:00:00 [WARNING] Port in use": "warning",
+		"[SUCCESS] Done": "info",
+		"2024-01-01 10:00:00 [debug]: Loading plugin":                    "debug",
+		"A long message from a plugin which only later mentions [error]": "info",
+	} {
+		if got := Level(line, "info"); got != want {
+			t.Errorf("Level(%q) = %q, want %q", line, got, want)
+		}
+	}
+	if got := Level("plain line", "warning"); got != "warning" {
+		t.Errorf("Level() without level = %q, want the fallback", got)
+	}
+}
@@ -0,0 +1,180 @@
+// Package netconf holds the proxy and TLS trust settings shared by the launchers in build-src.
+// They are read from the "network" section of launcher-settings.json next to the executable and
+// apply to the launchers' own downloads as well as to npm and the Node.js server.
+package netconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Settings configures proxy and TLS trust for all launcher network operations
+type Settings struct {
+	HTTPProxy  string `json:"http_proxy,omitempty"`
+	HTTPSProxy string `json:"https_proxy,omitempty"`
+	NoProxy    string `json:"no_proxy,omitempty"`  // Comma-separated hosts/domains that bypass the proxy
+	CABundle   string `json:"ca_bundle,omitempty"` // Path to a PEM file with additional CA certificates
+}
+
+// Load reads the network section of the settings file at path.
+// A missing file results in empty settings (environment proxy variables still apply).
+func Load(path string) (Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Settings{}, nil
+		}
+		return Settings{}, err
+	}
+
+	var settings struct {
+		Network Settings `json:"network"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Settings{}, err
+	}
+	return settings.Network, nil
+}
+
+// HTTPClient creates an HTTP client that honours the proxy and CA bundle. A timeout of 0 means
+// no timeout. If the CA bundle cannot be loaded, the client trusts the system roots only and the
+// error is returned alongside it.
+func (s Settings) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = s.ProxyFunc()
+
+	tlsConfig, err := s.TLSConfig()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, err
+}
+
+// ProxyFunc returns the proxy selector for outgoing requests.
+// Explicit launcher settings take precedence, otherwise HTTP_PROXY/HTTPS_PROXY/NO_PROXY from the environment apply.
+func (s Settings) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if s.HTTPProxy == "" && s.HTTPSProxy == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		host := req.URL.Hostname()
+		if isLoopbackHost(host) || s.bypassesProxy(host) {
+			return nil, nil
+		}
+
+		proxy := s.HTTPProxy
+		if req.URL.Scheme == "https" && s.HTTPSProxy != "" {
+			proxy = s.HTTPSProxy
+		}
+		if proxy == "" {
+			return nil, nil
+		}
+
+		// Accept "proxy:8080" as shorthand for "http://proxy:8080"
+		if !strings.Contains(proxy, "://") {
+			proxy = "http://" + proxy
+		}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy address %q: %v", proxy, err)
+		}
+		return proxyURL, nil
+	}
+}
+
+// bypassesProxy reports whether host matches an entry of the no-proxy list
+func (s Settings) bypassesProxy(host string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range strings.Split(s.NoProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		// Ignore ports in entries like "intranet.local:8080"
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		entry = strings.TrimPrefix(entry, ".")
+
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackHost reports whether host refers to the local machine
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// TLSConfig returns a TLS configuration trusting the system roots plus the configured CA bundle.
+// Returns nil if no CA bundle is configured.
+func (s Settings) TLSConfig() (*tls.Config, error) {
+	if s.CABundle == "" {
+		return nil, nil
+	}
+
+	pemData, err := os.ReadFile(s.CABundle)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", s.CABundle)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// ChildEnv returns environment entries that forward the settings to child processes.
+// npm and node-gyp read the npm_config_* and *_PROXY variables, Node.js itself reads NODE_EXTRA_CA_CERTS.
+// The CA bundle is deliberately not passed as npm "cafile", because that would replace the default
+// CA list instead of extending it.
+func (s Settings) ChildEnv() []string {
+	env := []string{}
+
+	if s.HTTPProxy != "" {
+		env = append(env, "HTTP_PROXY="+s.HTTPProxy, "http_proxy="+s.HTTPProxy, "npm_config_proxy="+s.HTTPProxy)
+	}
+
+	httpsProxy := s.HTTPSProxy
+	if httpsProxy == "" {
+		httpsProxy = s.HTTPProxy
+	}
+	if httpsProxy != "" {
+		env = append(env, "HTTPS_PROXY="+httpsProxy, "https_proxy="+httpsProxy, "npm_config_https_proxy="+httpsProxy)
+	}
+
+	if s.NoProxy != "" {
+		env = append(env, "NO_PROXY="+s.NoProxy, "no_proxy="+s.NoProxy, "npm_config_noproxy="+s.NoProxy)
+	}
+
+	if s.CABundle != "" {
+		env = append(env, "NODE_EXTRA_CA_CERTS="+s.CABundle)
+	}
+
+	return env
+}
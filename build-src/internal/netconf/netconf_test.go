@@ -0,0 +1,113 @@
+package netconf
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test proxy selection and no-proxy handling
+func TestProxyFunc(t *testing.T) {
+	settings := Settings{
+		HTTPProxy:  "proxy.corp:8080",
+		HTTPSProxy: "http://secure-proxy.corp:3128",
+		NoProxy:    "intranet.local, .corp.example",
+	}
+	proxy := settings.ProxyFunc()
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"http://registry.npmjs.org/express", "http://proxy.corp:8080"},
+		{"https://api.github.com/repos", "http://secure-proxy.corp:3128"},
+		{"http://localhost:3000/health", ""},
+		{"http://127.0.0.1:3000/health", ""},
+		{"https://intranet.local/file", ""},
+		{"https://build.corp.example/file", ""},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("GET", test.url, nil)
+		proxyURL, err := proxy(req)
+		if err != nil {
+			t.Fatalf("proxy(%s) returned error: %v", test.url, err)
+		}
+		got := ""
+		if proxyURL != nil {
+			got = proxyURL.String()
+		}
+		if got != test.expected {
+			t.Errorf("proxy(%s) = %q, expected %q", test.url, got, test.expected)
+		}
+	}
+}
+
+// Test that the settings are forwarded to npm and Node.js
+func TestChildEnv(t *testing.T) {
+	settings := Settings{
+		HTTPProxy: "http://proxy.corp:8080",
+		NoProxy:   "localhost",
+		CABundle:  "/etc/ssl/corp-ca.pem",
+	}
+
+	env := map[string]bool{}
+	for _, entry := range settings.ChildEnv() {
+		env[entry] = true
+	}
+
+	expected := []string{
+		"npm_config_proxy=http://proxy.corp:8080",
+		"npm_config_https_proxy=http://proxy.corp:8080", // Falls back to HTTP proxy
+		"HTTPS_PROXY=http://proxy.corp:8080",
+		"npm_config_noproxy=localhost",
+		"NODE_EXTRA_CA_CERTS=/etc/ssl/corp-ca.pem",
+	}
+	for _, entry := range expected {
+		if !env[entry] {
+			t.Errorf("Expected %q in child environment", entry)
+		}
+	}
+
+	if len(Settings{}.ChildEnv()) != 0 {
+		t.Error("Empty settings should not add environment variables")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "launcher-settings.json")
+
+	if settings, err := Load(path); err != nil || settings != (Settings{}) {
+		t.Errorf("Missing file: expected empty settings, got %+v, %v", settings, err)
+	}
+
+	os.WriteFile(path, []byte(`{"network": {"https_proxy": "proxy:3128", "ca_bundle": "ca.pem"}, "logging": {"format": "json"}}`), 0644)
+	settings, err := Load(path)
+	if err != nil || settings.HTTPSProxy != "proxy:3128" || settings.CABundle != "ca.pem" {
+		t.Errorf("Unexpected settings %+v, %v", settings, err)
+	}
+
+	os.WriteFile(path, []byte(`{"network": `), 0644)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+// Test that a broken CA bundle still yields a usable client
+func TestHTTPClient(t *testing.T) {
+	client, err := Settings{CABundle: filepath.Join(t.TempDir(), "missing.pem")}.HTTPClient(0)
+	if err == nil {
+		t.Error("Expected an error for a missing CA bundle")
+	}
+	if client == nil || client.Transport.(*http.Transport).Proxy == nil {
+		t.Fatal("Expected a client with proxy selection")
+	}
+
+	pemPath := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(pemPath, []byte("not a certificate"), 0644)
+	if _, err := (Settings{CABundle: pemPath}).TLSConfig(); err == nil {
+		t.Error("Expected an error for a file without certificates")
+	}
+}
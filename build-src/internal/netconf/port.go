@@ -0,0 +1,58 @@
+package netconf
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort is the port server.js uses without PORT
+const DefaultPort = 3000
+
+// ReadEnvFileValue returns the value of key in a .env file, "" if unset
+func ReadEnvFileValue(path, key string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	value := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 || strings.TrimSpace(strings.TrimPrefix(line[:eq], "export ")) != key {
+			continue
+		}
+		// Later assignments win, like in dotenv
+		value = strings.TrimSpace(line[eq+1:])
+		if hash := strings.Index(value, " #"); hash >= 0 {
+			value = strings.TrimSpace(value[:hash])
+		}
+		value = strings.Trim(value, `"'`)
+	}
+	return value
+}
+
+// ParsePort returns a valid TCP port or 0
+func ParsePort(value string) int {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || port < 1 || port > 65535 {
+		return 0
+	}
+	return port
+}
+
+// EffectivePort returns the port the server will listen on: PORT from the environment
+// (dotenv never overrides it), then PORT from app/.env, then the server.js default
+func EffectivePort(appDir string) int {
+	if port := ParsePort(os.Getenv("PORT")); port > 0 {
+		return port
+	}
+	if port := ParsePort(ReadEnvFileValue(filepath.Join(appDir, ".env"), "PORT")); port > 0 {
+		return port
+	}
+	return DefaultPort
+}
@@ -0,0 +1,50 @@
+package netconf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test reading a key from a .env file the way dotenv does
+func TestReadEnvFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("# PORT=1\nPORT=3001\nexport PORT = \"4000\" # comment\nHOST=localhost\n"), 0644)
+
+	if got := ReadEnvFileValue(path, "PORT"); got != "4000" {
+		t.Errorf("ReadEnvFileValue(PORT) = %q, want the last assignment 4000", got)
+	}
+	if got := ReadEnvFileValue(path, "MISSING"); got != "" {
+		t.Errorf("ReadEnvFileValue(MISSING) = %q, want empty", got)
+	}
+	if got := ReadEnvFileValue(filepath.Join(t.TempDir(), ".env"), "PORT"); got != "" {
+		t.Errorf("ReadEnvFileValue() without file = %q, want empty", got)
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	for value, want := range map[string]int{"3000": 3000, " 8080 ": 8080, "0": 0, "65536": 0, "abc": 0, "": 0} {
+		if got := ParsePort(value); got != want {
+			t.Errorf("ParsePort(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+// Test that PORT from the environment wins over app/.env and the default
+func TestEffectivePort(t *testing.T) {
+	appDir := t.TempDir()
+	t.Setenv("PORT", "")
+	if got := EffectivePort(appDir); got != DefaultPort {
+		t.Errorf("EffectivePort() without PORT = %d, want %d", got, DefaultPort)
+	}
+
+	os.WriteFile(filepath.Join(appDir, ".env"), []byte("PORT=3005\n"), 0644)
+	if got := EffectivePort(appDir); got != 3005 {
+		t.Errorf("EffectivePort() with app/.env = %d, want 3005", got)
+	}
+
+	t.Setenv("PORT", "3010")
+	if got := EffectivePort(appDir); got != 3010 {
+		t.Errorf("EffectivePort() with PORT = %d, want 3010", got)
+	}
+}
@@ -0,0 +1,241 @@
+// Package plugindeps verifies the npm modules that the enabled plugins of the app declare in
+// their plugin.json, so the launchers can install missing ones before the server starts.
+package plugindeps
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Manifest is the part of a plugin.json the check needs
+type Manifest struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Enabled      *bool           `json:"enabled"`
+	Dependencies json.RawMessage `json:"dependencies"`
+}
+
+// State is an entry of the plugin state file written by the plugin loader
+type State struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// Status describes the npm dependencies of an enabled plugin
+type Status struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Dependencies []string `json:"dependencies"`
+	Missing      []string `json:"missing"`
+	Installed    []string `json:"installed"` // Dependencies installed by the launcher during this run
+	Degraded     bool     `json:"degraded"`
+}
+
+// builtinModules lists core modules that plugins may declare but npm cannot install
+var builtinModules = map[string]bool{
+	"assert": true, "buffer": true, "child_process": true, "crypto": true, "dgram": true,
+	"dns": true, "events": true, "fs": true, "http": true, "https": true, "net": true,
+	"os": true, "path": true, "querystring": true, "readline": true, "stream": true,
+	"timers": true, "tls": true, "url": true, "util": true, "worker_threads": true, "zlib": true,
+}
+
+// DependencyNames returns the npm module names declared in the manifest.
+// Only the array form is supported; other forms (e.g. {"optional": [...]} for plugin
+// dependencies) are ignored. Version suffixes like "axios@^1.6.0" are stripped.
+func (m Manifest) DependencyNames() []string {
+	var deps []string
+	if len(m.Dependencies) == 0 || json.Unmarshal(m.Dependencies, &deps) != nil {
+		return nil
+	}
+
+	names := []string{}
+	for _, dep := range deps {
+		dep = strings.TrimSpace(dep)
+		// Keep the leading @ of scoped packages, strip the version spec
+		if idx := strings.LastIndex(dep, "@"); idx > 0 {
+			dep = dep[:idx]
+		}
+		if dep == "" || strings.HasPrefix(dep, "node:") || builtinModules[dep] {
+			continue
+		}
+		names = append(names, dep)
+	}
+	return names
+}
+
+var (
+	profileNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+	meaningfulPattern  = regexp.MustCompile(`[^_-]`)
+)
+
+// sanitizeProfileName mirrors PluginLoader.sanitizeProfileName of app/modules/plugin-loader.js
+func sanitizeProfileName(name string) string {
+	sanitized := profileNamePattern.ReplaceAllString(name, "_")
+	if meaningfulPattern.MatchString(sanitized) {
+		return sanitized
+	}
+	sum := md5.Sum([]byte(name))
+	return "profile_" + hex.EncodeToString(sum[:])[:8]
+}
+
+// ActiveProfile returns the profile the server loads from userConfigsDir: the one named in
+// .active_profile if its database exists, otherwise "default", which the server creates then.
+func ActiveProfile(userConfigsDir string) string {
+	data, err := os.ReadFile(filepath.Join(userConfigsDir, ".active_profile"))
+	if err != nil {
+		return "default"
+	}
+	profile := strings.TrimSpace(string(data))
+	if profile == "" {
+		return "default"
+	}
+	if _, err := os.Stat(filepath.Join(userConfigsDir, profileNamePattern.ReplaceAllString(profile, "_")+".db")); err != nil {
+		return "default"
+	}
+	return profile
+}
+
+// StateFile returns the plugin state file the plugin loader reads for profile:
+// <userConfigsDir>/<profile>_plugins_state.json. Until the loader has created it, it migrates
+// the legacy app/plugins/plugins_state.json, so that file applies instead.
+func StateFile(appDir, userConfigsDir, profile string) string {
+	stateFile := filepath.Join(userConfigsDir, "plugins_state.json")
+	if profile != "" {
+		stateFile = filepath.Join(userConfigsDir, sanitizeProfileName(profile)+"_plugins_state.json")
+	}
+	if _, err := os.Stat(stateFile); err == nil {
+		return stateFile
+	}
+	return filepath.Join(appDir, "plugins", "plugins_state.json")
+}
+
+// loadStates reads a plugin state file; the saved state overrides the enabled flag of plugin.json
+func loadStates(stateFile string) map[string]State {
+	states := map[string]State{}
+	data, err := os.ReadFile(stateFile)
+	if err == nil {
+		json.Unmarshal(data, &states)
+	}
+	return states
+}
+
+// moduleResolves checks whether a module is installed in appDir/node_modules
+func moduleResolves(appDir, module string) bool {
+	parts := append([]string{appDir, "node_modules"}, strings.Split(module, "/")...)
+	_, err := os.Stat(filepath.Join(append(parts, "package.json")...))
+	return err == nil
+}
+
+// Check verifies the dependencies declared by all plugins in appDir/plugins that are enabled
+// according to stateFile. Invalid manifests are skipped and returned as warnings.
+func Check(appDir, stateFile string) (statuses []Status, warnings []error, err error) {
+	pluginsDir := filepath.Join(appDir, "plugins")
+	entries, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	states := loadStates(stateFile)
+	statuses = []Status{}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(pluginsDir, entry.Name(), "plugin.json"))
+		if err != nil {
+			continue
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			warnings = append(warnings, &ManifestError{Plugin: entry.Name(), Err: err})
+			continue
+		}
+		if manifest.ID == "" {
+			manifest.ID = entry.Name()
+		}
+		if manifest.Name == "" {
+			manifest.Name = manifest.ID
+		}
+
+		// Same rule as the plugin loader: saved state wins, otherwise enabled unless explicitly false
+		enabled := manifest.Enabled == nil || *manifest.Enabled
+		if state, ok := states[manifest.ID]; ok && state.Enabled != nil {
+			enabled = *state.Enabled
+		}
+		deps := manifest.DependencyNames()
+		if !enabled || len(deps) == 0 {
+			continue
+		}
+
+		status := Status{
+			ID:           manifest.ID,
+			Name:         manifest.Name,
+			Dependencies: deps,
+			Missing:      []string{},
+			Installed:    []string{},
+		}
+		for _, dep := range deps {
+			if !moduleResolves(appDir, dep) {
+				status.Missing = append(status.Missing, dep)
+			}
+		}
+		status.Degraded = len(status.Missing) > 0
+		statuses = append(statuses, status)
+	}
+
+	return statuses, warnings, nil
+}
+
+// ManifestError reports a plugin.json that could not be parsed
+type ManifestError struct {
+	Plugin string
+	Err    error
+}
+
+func (e *ManifestError) Error() string {
+	return "invalid plugin.json in " + e.Plugin + ": " + e.Err.Error()
+}
+
+// MissingModules returns the missing modules of all plugins, each module once
+func MissingModules(statuses []Status) []string {
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, status := range statuses {
+		for _, dep := range status.Missing {
+			if !seen[dep] {
+				seen[dep] = true
+				missing = append(missing, dep)
+			}
+		}
+	}
+	return missing
+}
+
+// InstallArgs returns the npm arguments that install the missing modules.
+// --no-save keeps package.json untouched, so updates are not blocked by local changes.
+func InstallArgs(missing []string) []string {
+	return append([]string{"install", "--no-save", "--omit=dev", "--no-audit", "--no-fund"}, missing...)
+}
+
+// Recheck updates the statuses after an installation: modules that resolve now move from
+// Missing to Installed, plugins stay degraded while any module is still missing
+func Recheck(appDir string, statuses []Status) {
+	for i := range statuses {
+		stillMissing := []string{}
+		for _, dep := range statuses[i].Missing {
+			if moduleResolves(appDir, dep) {
+				statuses[i].Installed = append(statuses[i].Installed, dep)
+			} else {
+				stillMissing = append(stillMissing, dep)
+			}
+		}
+		statuses[i].Missing = stillMissing
+		statuses[i].Degraded = len(stillMissing) > 0
+	}
+}
@@ -0,0 +1,129 @@
+package plugindeps
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDependencyNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     string
+		expected []string
+	}{
+		{"array", `["axios", "franc-min"]`, []string{"axios", "franc-min"}},
+		{"version specs", `["axios@^1.6.0", "@scope/pkg@2.0.0", "@scope/other"]`, []string{"axios", "@scope/pkg", "@scope/other"}},
+		{"builtins skipped", `["fs", "node:path", "ws"]`, []string{"ws"}},
+		{"plugin dependency object ignored", `{"optional": ["openshock"]}`, nil},
+	}
+	for _, tt := range tests {
+		manifest := Manifest{Dependencies: json.RawMessage(tt.deps)}
+		if got := manifest.DependencyNames(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: DependencyNames() = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+	if got := (Manifest{}).DependencyNames(); got != nil {
+		t.Errorf("Missing dependencies: expected nil, got %v", got)
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	appDir := t.TempDir()
+	userConfigsDir := t.TempDir()
+	legacy := filepath.Join(appDir, "plugins", "plugins_state.json")
+
+	if got := StateFile(appDir, userConfigsDir, "streamer.one"); got != legacy {
+		t.Errorf("Without profile state: expected legacy file, got %s", got)
+	}
+
+	scoped := filepath.Join(userConfigsDir, "streamer_one_plugins_state.json")
+	writeFile(t, scoped, `{}`)
+	if got := StateFile(appDir, userConfigsDir, "streamer.one"); got != scoped {
+		t.Errorf("Expected %s, got %s", scoped, got)
+	}
+
+	// Names without letters or digits get the hashed name of the plugin loader
+	if got := sanitizeProfileName("@@"); !strings.HasPrefix(got, "profile_") || len(got) != len("profile_")+8 {
+		t.Errorf("Unexpected sanitized name %q", got)
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	userConfigsDir := t.TempDir()
+	if got := ActiveProfile(userConfigsDir); got != "default" {
+		t.Errorf("Without .active_profile: expected default, got %s", got)
+	}
+
+	writeFile(t, filepath.Join(userConfigsDir, ".active_profile"), "streamer\n")
+	if got := ActiveProfile(userConfigsDir); got != "default" {
+		t.Errorf("Without database: expected default, got %s", got)
+	}
+
+	writeFile(t, filepath.Join(userConfigsDir, "streamer.db"), "")
+	if got := ActiveProfile(userConfigsDir); got != "streamer" {
+		t.Errorf("Expected streamer, got %s", got)
+	}
+}
+
+// Test that only plugins enabled for the profile are checked and missing modules are reported per plugin
+func TestCheck(t *testing.T) {
+	appDir := t.TempDir()
+	userConfigsDir := t.TempDir()
+	writeFile(t, filepath.Join(appDir, "node_modules/axios/package.json"), `{"name": "axios"}`)
+	writeFile(t, filepath.Join(appDir, "plugins/tts/plugin.json"), `{"id": "tts", "name": "TTS", "enabled": true, "dependencies": ["axios", "franc-min"]}`)
+	writeFile(t, filepath.Join(appDir, "plugins/soundboard/plugin.json"), `{"id": "soundboard", "enabled": true, "dependencies": ["axios"]}`)
+	writeFile(t, filepath.Join(appDir, "plugins/multicam/plugin.json"), `{"id": "multicam", "enabled": false, "dependencies": ["obs-websocket-js"]}`)
+	writeFile(t, filepath.Join(appDir, "plugins/talking-heads/plugin.json"), `{"id": "talking-heads", "enabled": false, "dependencies": ["sharp"]}`)
+	writeFile(t, filepath.Join(appDir, "plugins/broken/plugin.json"), `{"id": `)
+	// The legacy file no longer applies once the profile has its own state
+	writeFile(t, filepath.Join(appDir, "plugins/plugins_state.json"), `{"multicam": {"enabled": true}}`)
+	writeFile(t, filepath.Join(userConfigsDir, "streamer_plugins_state.json"), `{"talking-heads": {"enabled": true}, "tts": {"enabled": false}}`)
+
+	statuses, warnings, err := Check(appDir, StateFile(appDir, userConfigsDir, "streamer"))
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "broken") {
+		t.Errorf("Expected a warning for the broken manifest, got %v", warnings)
+	}
+
+	byID := map[string]Status{}
+	for _, status := range statuses {
+		byID[status.ID] = status
+	}
+	if _, ok := byID["multicam"]; ok {
+		t.Error("multicam: enabled only in the legacy state file, should not be checked")
+	}
+	if _, ok := byID["tts"]; ok {
+		t.Error("tts: disabled in the profile state, should not be checked")
+	}
+	if status := byID["soundboard"]; status.Degraded || status.Name != "soundboard" {
+		t.Errorf("soundboard: expected satisfied with name fallback, got %+v", status)
+	}
+	if status, ok := byID["talking-heads"]; !ok || !status.Degraded || strings.Join(status.Missing, ",") != "sharp" {
+		t.Errorf("talking-heads: enabled in the profile state, expected missing sharp, got %+v", status)
+	}
+
+	if missing := MissingModules(statuses); !reflect.DeepEqual(missing, []string{"sharp"}) {
+		t.Errorf("MissingModules() = %v", missing)
+	}
+	writeFile(t, filepath.Join(appDir, "node_modules/sharp/package.json"), `{"name": "sharp"}`)
+	Recheck(appDir, statuses)
+	for _, status := range statuses {
+		if status.ID == "talking-heads" && (status.Degraded || strings.Join(status.Installed, ",") != "sharp") {
+			t.Errorf("talking-heads after install: got %+v", status)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package proctree starts a process so that it can later be terminated together with
+// everything it spawned. npm runs through cmd /C and starts node-gyp and compilers, whose
+// parents may already have exited by the time an install is cancelled.
+//
+// On Unix the process gets its own process group. On Windows it is assigned to a Job
+// Object, which also contains grandchildren whose parent has exited.
+package proctree
+
+import (
+	"os/exec"
+	"sync"
+)
+
+var (
+	treesMutex sync.Mutex
+	trees      = make(map[*exec.Cmd]*tree)
+)
+
+// Start starts cmd as the root of a process tree that Kill can terminate
+func Start(cmd *exec.Cmd) error {
+	prepare(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	t, err := attach(cmd)
+	if err != nil {
+		// A process that could not be resumed would hang forever
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	treesMutex.Lock()
+	trees[cmd] = t
+	treesMutex.Unlock()
+	return nil
+}
+
+// Kill terminates cmd and all processes started by it. Commands that were not started
+// through Start are terminated as well as the platform allows.
+func Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	treesMutex.Lock()
+	t := trees[cmd]
+	treesMutex.Unlock()
+	if err := kill(cmd, t); err != nil {
+		// At least terminate the direct child
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// Release frees the resources held for cmd. Call it after cmd.Wait returned.
+func Release(cmd *exec.Cmd) {
+	treesMutex.Lock()
+	t := trees[cmd]
+	delete(trees, cmd)
+	treesMutex.Unlock()
+	if t != nil {
+		t.close()
+	}
+}
@@ -0,0 +1,73 @@
+package proctree
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTree starts a shell that spawns a grandchild and returns the grandchild's PID
+func startTree(t *testing.T, script string) (*exec.Cmd, int) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("Process tree test uses /proc")
+	}
+
+	cmd := exec.Command("sh", "-c", script)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	if err := Start(cmd); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	t.Cleanup(func() { Release(cmd) })
+
+	var childPID int
+	if _, err := fmt.Fscan(stdout, &childPID); err != nil {
+		t.Fatalf("Failed to read child PID: %v", err)
+	}
+	return cmd, childPID
+}
+
+// waitGone fails unless pid exits (or becomes a zombie waiting to be reaped)
+func waitGone(t *testing.T, pid int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil || strings.Contains(string(stat), ") Z ") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Grandchild %d still running after Kill", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Test that Kill also terminates grandchildren (npm -> node-gyp)
+func TestKill(t *testing.T) {
+	cmd, childPID := startTree(t, "sleep 30 & echo $!; wait")
+
+	if err := Kill(cmd); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	cmd.Wait()
+	waitGone(t, childPID)
+}
+
+// Test that Kill reaches grandchildren whose parent has already exited
+func TestKillOrphanedGrandchild(t *testing.T) {
+	cmd, childPID := startTree(t, "sleep 30 >/dev/null 2>&1 & echo $!")
+	cmd.Wait()
+
+	if err := Kill(cmd); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	waitGone(t, childPID)
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package proctree
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// tree needs no state on Unix, the process group ID equals the PID of cmd
+type tree struct{}
+
+// prepare makes cmd the leader of a new process group
+func prepare(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+func attach(cmd *exec.Cmd) (*tree, error) {
+	return &tree{}, nil
+}
+
+// kill signals the process group; a negative PID addresses the whole group
+func kill(cmd *exec.Cmd, t *tree) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func (t *tree) close() {}
@@ -0,0 +1,53 @@
+// Package readiness reads the startup stages the Node.js server reports to the launchers, see
+// app/modules/launcher-readiness.js. launcher-gui.exe receives them as POST requests on the URL
+// in URLEnv, the console launcher polls the file in FileEnv.
+package readiness
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Environment variables that tell the server where to report its stages
+const (
+	FileEnv = "LTTH_READY_FILE"
+	URLEnv  = "LTTH_READY_URL"
+)
+
+// Stages are the startup stages the server reports, in order
+var Stages = []string{"starting", "database", "plugins", "listening", "ready"}
+
+// Report is a startup stage reported by the server
+type Report struct {
+	Stage   string `json:"stage"`
+	Port    int    `json:"port,omitempty"` // Port the server actually listens on, from "listening" on
+	Version string `json:"version,omitempty"`
+	PID     int    `json:"pid,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// StageIndex returns the position of stage in Stages, -1 if unknown
+func StageIndex(stage string) int {
+	for i, s := range Stages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// ReadFile returns the stage last written by the server, ignoring files of other runs
+func ReadFile(path, token string) (Report, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, false
+	}
+	var report struct {
+		Report
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil || report.Token != token || StageIndex(report.Stage) < 0 {
+		return Report{}, false
+	}
+	return report.Report, true
+}
@@ -0,0 +1,39 @@
+package readiness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStageIndex(t *testing.T) {
+	if StageIndex("starting") != 0 || StageIndex("ready") != len(Stages)-1 {
+		t.Errorf("Unexpected order of %v", Stages)
+	}
+	if StageIndex("unknown") != -1 || StageIndex("") != -1 {
+		t.Error("Expected -1 for unknown stages")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ready.json")
+	if _, ok := ReadFile(path, "secret"); ok {
+		t.Error("Expected no report without a file")
+	}
+
+	os.WriteFile(path, []byte(`{"stage":"ready","port":3001,"version":"1.3.2","token":"other"}`), 0644)
+	if _, ok := ReadFile(path, "secret"); ok {
+		t.Error("Reports of other runs must be ignored")
+	}
+
+	os.WriteFile(path, []byte(`{"stage":"booting","token":"secret"}`), 0644)
+	if _, ok := ReadFile(path, "secret"); ok {
+		t.Error("Unknown stages must be ignored")
+	}
+
+	os.WriteFile(path, []byte(`{"stage":"ready","port":3001,"version":"1.3.2","token":"secret"}`), 0644)
+	report, ok := ReadFile(path, "secret")
+	if !ok || report.Port != 3001 || report.Version != "1.3.2" {
+		t.Errorf("Unexpected report: %+v (%v)", report, ok)
+	}
+}
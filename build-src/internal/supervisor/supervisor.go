@@ -0,0 +1,121 @@
+// Package supervisor decides when a crashed Node.js server is restarted and asks a running
+// server to shut down gracefully. Both launchers read its settings from the "supervisor"
+// section of launcher-settings.json.
+package supervisor
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Loggableim/pupcidslittletiktokhelper/internal/launcherauth"
+)
+
+const (
+	defaultCrashLimit         = 5 // Crashes within the window that count as crash loop
+	defaultCrashWindowMinutes = 10
+	initialRestartBackoff     = 2 * time.Second // Doubled for every consecutive crash
+	maxRestartBackoff         = 2 * time.Minute
+	stableRunDuration         = 5 * time.Minute // A server that ran this long resets the backoff
+
+	// DefaultShutdownGrace is the time the server gets to close databases before it is killed
+	DefaultShutdownGrace = 10 * time.Second
+
+	// TokenEnv passes the session token to the server, which requires it for the shutdown request
+	TokenEnv = "LTTH_LAUNCHER_TOKEN"
+)
+
+// Settings configures automatic restarts of the Node.js server after a crash
+type Settings struct {
+	Disabled           bool `json:"disabled,omitempty"`    // Exit together with the server instead of restarting it
+	CrashLimit         int  `json:"crash_limit,omitempty"` // Give up after this many crashes within the window
+	CrashWindowMinutes int  `json:"crash_window_minutes,omitempty"`
+	ShutdownGraceSecs  int  `json:"shutdown_grace_seconds,omitempty"` // Time to flush databases before the server is killed
+}
+
+// WithDefaults fills unset values with the defaults
+func (s Settings) WithDefaults() Settings {
+	if s.CrashLimit <= 0 {
+		s.CrashLimit = defaultCrashLimit
+	}
+	if s.CrashWindowMinutes <= 0 {
+		s.CrashWindowMinutes = defaultCrashWindowMinutes
+	}
+	if s.ShutdownGraceSecs <= 0 {
+		s.ShutdownGraceSecs = int(DefaultShutdownGrace / time.Second)
+	}
+	return s
+}
+
+// ShutdownGrace returns the grace period between the shutdown request and killing the server
+func (s Settings) ShutdownGrace() time.Duration {
+	return time.Duration(s.ShutdownGraceSecs) * time.Second
+}
+
+// Policy decides whether and when a crashed server is restarted
+type Policy struct {
+	settings    Settings
+	crashes     []time.Time // Crashes within the window
+	consecutive int         // Crashes since the last stable run
+}
+
+// NewPolicy returns a policy without recorded crashes
+func NewPolicy(settings Settings) *Policy {
+	return &Policy{settings: settings.WithDefaults()}
+}
+
+// Next records a crash after the server ran for uptime and returns the delay before the restart.
+// restart is false once the crash limit is reached within the window (crash loop).
+func (p *Policy) Next(now time.Time, uptime time.Duration) (time.Duration, bool) {
+	window := time.Duration(p.settings.CrashWindowMinutes) * time.Minute
+	recent := []time.Time{}
+	for _, crash := range p.crashes {
+		if now.Sub(crash) < window {
+			recent = append(recent, crash)
+		}
+	}
+	p.crashes = append(recent, now)
+
+	if uptime >= stableRunDuration {
+		p.consecutive = 0
+	}
+	p.consecutive++
+	if len(p.crashes) >= p.settings.CrashLimit {
+		return 0, false
+	}
+
+	delay := initialRestartBackoff
+	for i := 1; i < p.consecutive && delay < maxRestartBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRestartBackoff {
+		delay = maxRestartBackoff
+	}
+	return delay, true
+}
+
+// Crashes returns the number of crashes within the window, including the last one
+func (p *Policy) Crashes() int {
+	return len(p.crashes)
+}
+
+// RequestShutdown asks the server on the loopback port to shut down gracefully. The GUI runs
+// the server without a console window, so it cannot receive Ctrl+C there.
+func RequestShutdown(port int, token string) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://127.0.0.1:%d/api/launcher/shutdown", port), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(launcherauth.Header, token)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
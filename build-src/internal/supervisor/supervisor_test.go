@@ -0,0 +1,72 @@
+package supervisor
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Test crash-loop detection and backoff of the server supervisor
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy(Settings{CrashLimit: 3, CrashWindowMinutes: 5})
+	now := time.Now()
+
+	if delay, restart := policy.Next(now, time.Second); !restart || delay != initialRestartBackoff {
+		t.Errorf("Expected first restart after %s, got %s (restart=%v)", initialRestartBackoff, delay, restart)
+	}
+	if delay, restart := policy.Next(now.Add(time.Minute), time.Second); !restart || delay != 2*initialRestartBackoff {
+		t.Errorf("Expected doubled backoff, got %s (restart=%v)", delay, restart)
+	}
+	if _, restart := policy.Next(now.Add(2*time.Minute), time.Second); restart {
+		t.Error("Expected crash loop after 3 crashes within 5 minutes")
+	}
+	if _, restart := policy.Next(now.Add(20*time.Minute), time.Second); !restart {
+		t.Error("Crashes outside the window must not count")
+	}
+	if policy.Crashes() != 1 {
+		t.Errorf("Expected 1 crash within the window, got %d", policy.Crashes())
+	}
+}
+
+// Test that a stable run resets the backoff and that it never exceeds the maximum
+func TestPolicyBackoff(t *testing.T) {
+	policy := NewPolicy(Settings{CrashLimit: 100, CrashWindowMinutes: 1})
+	now := time.Now()
+	var delay time.Duration
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Hour)
+		delay, _ = policy.Next(now, time.Second)
+	}
+	if delay != maxRestartBackoff {
+		t.Errorf("Expected backoff capped at %s, got %s", maxRestartBackoff, delay)
+	}
+	if delay, _ = policy.Next(now.Add(time.Hour), stableRunDuration); delay != initialRestartBackoff {
+		t.Errorf("Expected backoff reset after a stable run, got %s", delay)
+	}
+}
+
+func TestRequestShutdown(t *testing.T) {
+	if grace := (Settings{}).WithDefaults().ShutdownGrace(); grace != DefaultShutdownGrace {
+		t.Errorf("Expected default grace period %s, got %s", DefaultShutdownGrace, grace)
+	}
+
+	var gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/launcher/shutdown" {
+			http.NotFound(w, r)
+			return
+		}
+		gotToken = r.Header.Get("X-Launcher-Token")
+	}))
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+	if err := RequestShutdown(port, "secret"); err != nil || gotToken != "secret" {
+		t.Errorf("Expected shutdown request with token, got %q (%v)", gotToken, err)
+	}
+	server.Close()
+
+	if err := RequestShutdown(port, "secret"); err == nil {
+		t.Error("Expected an error without a listening server")
+	}
+}
@@ -0,0 +1,32 @@
+// Package syncfolder recognises folders of cloud sync services. The launchers warn about an
+// installation or configuration in such a folder: the service locks and replaces the SQLite
+// databases and node_modules while the server uses them.
+package syncfolder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Provider returns the cloud sync service whose folder contains dir, "" if none
+func Provider(dir string) string {
+	clean := strings.ToLower(filepath.Clean(dir))
+	for _, env := range []string{"OneDrive", "OneDriveConsumer", "OneDriveCommercial"} {
+		root := strings.ToLower(os.Getenv(env))
+		if root != "" && (clean == filepath.Clean(root) || strings.HasPrefix(clean, filepath.Clean(root)+string(filepath.Separator))) {
+			return "OneDrive"
+		}
+	}
+	for _, part := range strings.Split(filepath.ToSlash(clean), "/") {
+		switch {
+		case strings.HasPrefix(part, "onedrive"):
+			return "OneDrive"
+		case part == "dropbox" || strings.HasPrefix(part, "dropbox ("):
+			return "Dropbox"
+		case part == "iclouddrive" || part == "icloud drive" || part == "mobile documents":
+			return "iCloud"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package syncfolder
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProvider(t *testing.T) {
+	base := t.TempDir()
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{dir: filepath.Join(base, "LTTH"), want: ""},
+		{dir: filepath.Join(base, "OneDrive - Firma", "LTTH"), want: "OneDrive"},
+		{dir: filepath.Join(base, "Dropbox", "LTTH"), want: "Dropbox"},
+		{dir: filepath.Join(base, "Dropbox (Firma)", "LTTH"), want: "Dropbox"},
+		{dir: filepath.Join(base, "Library", "Mobile Documents", "LTTH"), want: "iCloud"},
+		{dir: filepath.Join(base, "Dropboxes", "LTTH"), want: ""},
+	}
+	for _, tt := range tests {
+		if got := Provider(tt.dir); got != tt.want {
+			t.Errorf("Provider(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+
+	// A OneDrive folder without "onedrive" in its name is recognised by the environment
+	root := filepath.Join(base, "Firma GmbH")
+	t.Setenv("OneDriveCommercial", root)
+	if got := Provider(filepath.Join(root, "LTTH")); got != "OneDrive" {
+		t.Errorf("Provider() below %%OneDriveCommercial%% = %q, want OneDrive", got)
+	}
+	if got := Provider(root + "2"); got != "" {
+		t.Errorf("Provider() next to %%OneDriveCommercial%% = %q, want empty", got)
+	}
+}
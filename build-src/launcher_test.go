@@ -1,10 +1,19 @@
 package main
 
 import (
+	"crypto/x509"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/Loggableim/pupcidslittletiktokhelper/internal/crash"
+	"github.com/Loggableim/pupcidslittletiktokhelper/internal/readiness"
 )
 
 // Test shouldCheckForUpdates rate limiting
@@ -293,3 +302,231 @@ func TestDetectUpdateMode(t *testing.T) {
 	}
 }
 
+// ============================================
+// Tests for proxy and CA settings
+// ============================================
+
+// Test that npmCommand forwards arguments and network settings
+func TestNpmCommand(t *testing.T) {
+	cmd := npmCommand(t.TempDir(), "/usr/bin/node", "install", "--cache", "false")
+	
+	args := strings.Join(cmd.Args, " ")
+	if !strings.HasSuffix(args, "npm install --cache false") {
+		t.Errorf("Unexpected npm command: %s", args)
+	}
+	if runtime.GOOS == "windows" && cmd.Args[0] != "cmd" {
+		t.Errorf("Expected npm to be started via cmd /C on Windows, got %s", args)
+	}
+	if len(cmd.Env) == 0 {
+		t.Error("Expected environment to be set")
+	}
+}
+
+// Test node_modules verification against package-lock.json
+func TestVerifyNodeModules(t *testing.T) {
+	appDir := t.TempDir()
+	writeFile := func(path, content string) {
+		fullPath := filepath.Join(appDir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(fullPath), 0755)
+		os.WriteFile(fullPath, []byte(content), 0644)
+	}
+	
+	writeFile("package-lock.json", `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": {"name": "app"},
+			"node_modules/express": {"version": "4.18.2"},
+			"node_modules/axios": {"version": "1.6.0"},
+			"node_modules/bcrypt": {"version": "6.0.0"},
+			"node_modules/jest": {"version": "29.0.0", "dev": true}
+		}
+	}`)
+	writeFile("node_modules/express/package.json", `{"version": "4.18.2", "main": "./lib/express"}`)
+	writeFile("node_modules/express/lib/express.js", ``)
+	writeFile("node_modules/axios/package.json", `{"version": "1.6.0", "main": "index.js"}`)
+	writeFile("node_modules/bcrypt/package.json", `{"version": "6.0.0"}`)
+	writeFile("node_modules/bcrypt/binding.gyp", `{}`)
+	writeFile("node_modules/bcrypt/prebuilds/win32-x64/bcrypt.node", `binary`)
+	
+	broken, err := verifyNodeModules(appDir)
+	if err != nil {
+		t.Fatalf("verifyNodeModules failed: %v", err)
+	}
+	
+	// Only axios is broken (main file quarantined); dev packages are not checked
+	if len(broken) != 1 || broken[0].Name != "axios" || broken[0].Problem != "main-Datei fehlt: index.js" {
+		t.Errorf("Expected only axios with missing main file, got %+v", broken)
+	}
+}
+
+// Test doctor severity aggregation and exit codes
+func TestDoctorReportSeverity(t *testing.T) {
+	report := &DoctorReport{Severity: severityOK}
+	report.add("A", "info", severityInfo, "", "")
+	if report.exitCode() != 0 {
+		t.Errorf("Expected exit code 0 for info findings, got %d", report.exitCode())
+	}
+	report.add("A", "warning", severityWarning, "", "")
+	if report.exitCode() != doctorExitWarning {
+		t.Errorf("Expected warning exit code, got %d", report.exitCode())
+	}
+	report.add("B", "error", severityError, "", "")
+	report.add("B", "ok", severityOK, "", "")
+	if report.Severity != severityError || report.exitCode() != doctorExitError {
+		t.Errorf("Expected error severity, got %s (%d)", report.Severity, report.exitCode())
+	}
+}
+
+// Test doctor profile check against user_configs and .active_profile
+func TestDoctorProfiles(t *testing.T) {
+	configDir := t.TempDir()
+	appDir := t.TempDir()
+	
+	report := &DoctorReport{Severity: severityOK}
+	doctorProfiles(report, configDir, appDir)
+	if report.Findings[0].Severity != severityInfo {
+		t.Errorf("Expected info without profiles, got %+v", report.Findings[0])
+	}
+	
+	userConfigs := filepath.Join(configDir, "user_configs")
+	os.MkdirAll(userConfigs, 0755)
+	os.WriteFile(filepath.Join(userConfigs, "streamer.db"), []byte{}, 0644)
+	os.WriteFile(filepath.Join(userConfigs, ".active_profile"), []byte("streamer\n"), 0644)
+	report = &DoctorReport{Severity: severityOK}
+	doctorProfiles(report, configDir, appDir)
+	if report.Severity != severityOK || !strings.Contains(report.Findings[0].Detail, "aktiv: streamer") {
+		t.Errorf("Expected active profile streamer, got %+v", report.Findings[0])
+	}
+	
+	os.WriteFile(filepath.Join(userConfigs, ".active_profile"), []byte("deleted"), 0644)
+	report = &DoctorReport{Severity: severityOK}
+	doctorProfiles(report, configDir, appDir)
+	if report.Severity != severityWarning {
+		t.Errorf("Expected warning for missing active profile, got %+v", report.Findings[0])
+	}
+}
+
+// Test .env validation without reporting values
+func TestDoctorEnvFile(t *testing.T) {
+	appDir := t.TempDir()
+	os.WriteFile(filepath.Join(appDir, ".env"), []byte("PORT=99999\nAPI_KEY=secret-value-123\nbroken line\n"), 0644)
+	
+	report := &DoctorReport{Severity: severityOK}
+	doctorEnvFile(report, appDir)
+	finding := report.Findings[0]
+	if finding.Severity != severityWarning || !strings.Contains(finding.Detail, "ungültige Zeilen: 3") || !strings.Contains(finding.Detail, "PORT") {
+		t.Errorf("Unexpected finding: %+v", finding)
+	}
+	if strings.Contains(finding.Detail, "secret-value") {
+		t.Error(".env values must not be reported")
+	}
+}
+
+// Test install path checks for sync folders, node-gyp characters and network mounts
+func TestCheckInstallPath(t *testing.T) {
+	if warnings := checkInstallPath(filepath.Join(t.TempDir(), "LTTH")); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for a local temp dir, got %+v", warnings)
+	}
+	
+	warnings := checkInstallPath(filepath.Join(t.TempDir(), "OneDrive - Firma", "LTTH"))
+	if len(warnings) != 1 || warnings[0].Kind != "sync_folder" {
+		t.Errorf("Expected sync_folder warning, got %+v", warnings)
+	}
+	
+	if chars := nodeGypUnsafeChars(`C:\Tools & Co\Jürgen`); strings.Join(chars, "") != "&ü" {
+		t.Errorf("Expected &ü, got %v", chars)
+	}
+	
+	entries := parseProcMounts("/dev/sda1 / ext4 rw 0 0\n//srv/share /mnt/share cifs rw 0 0\n")
+	if fsType := mountFSType(entries, "/mnt/share/LTTH"); !networkFSTypes[fsType] {
+		t.Errorf("Expected network filesystem for /mnt/share, got %q", fsType)
+	}
+	if fsType := mountFSType(entries, "/mnt/shared"); fsType != "ext4" {
+		t.Errorf("Expected ext4 for /mnt/shared, got %q", fsType)
+	}
+}
+
+// Test the disk space and network helpers of the doctor
+func TestDoctorPreflight(t *testing.T) {
+	free, mount, err := parseDfOutput("Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sdb1 1000 400 600 40% /media/USB Stick\n")
+	if err != nil || free != 600*1024 || mount != "/media/USB Stick" {
+		t.Errorf("Expected 600 KB on /media/USB Stick, got %d %q %v", free, mount, err)
+	}
+	if _, _, err := parseDfOutput("df: /nope: No such file or directory"); err == nil {
+		t.Error("Expected error for df output without a table")
+	}
+	
+	if formatBytes(500<<20) != "500 MB" || formatBytes(3<<29) != "1.5 GB" {
+		t.Errorf("Unexpected sizes %s, %s", formatBytes(500<<20), formatBytes(3<<29))
+	}
+	
+	problem, _ := classifyProbeError(&url.Error{Op: "Head", URL: "https://registry.npmjs.org/", Err: &net.DNSError{Err: "no such host", Name: "registry.npmjs.org", IsNotFound: true}})
+	if problem != "DNS: Host nicht gefunden" {
+		t.Errorf("Expected DNS problem, got %q", problem)
+	}
+	problem, hint := classifyProbeError(x509.UnknownAuthorityError{})
+	if !strings.HasPrefix(problem, "TLS-Interception") || !strings.Contains(hint, "ca_bundle") {
+		t.Errorf("Expected TLS interception with ca_bundle hint, got %q / %q", problem, hint)
+	}
+}
+
+// Test integrity verification against a GitHub tree
+func TestVerifyInstallation(t *testing.T) {
+	baseDir := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(baseDir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+	write("app/server.js", "hello\n")
+	write("plugins/demo/index.js", "tampered")
+	write("app/extra.js", "extra")
+	write("app/.env", "PORT=3000")
+	write("app/node_modules/express/index.js", "x")
+	write("runtime/version_sha.txt", "abc123\n")
+	
+	if sha, _ := gitBlobSHA(filepath.Join(baseDir, "app", "server.js")); sha != "ce013625030ba8dba906f756967f9e9ca394464a" {
+		t.Errorf("Unexpected blob SHA %s", sha)
+	}
+	if ref, err := installedRef(baseDir); err != nil || ref != "abc123" {
+		t.Errorf("Expected installed ref abc123, got %q (%v)", ref, err)
+	}
+	
+	items := []GitHubTreeItem{
+		{Path: "app", Type: "tree"},
+		{Path: "app/server.js", Type: "blob", SHA: "ce013625030ba8dba906f756967f9e9ca394464a"},
+		{Path: "app/package.json", Type: "blob", SHA: "0000000000000000000000000000000000000000"},
+		{Path: "plugins/demo/index.js", Type: "blob", SHA: "ce013625030ba8dba906f756967f9e9ca394464a"},
+		{Path: "README.md", Type: "blob", SHA: "0000000000000000000000000000000000000000"},
+	}
+	report := verifyInstallation(baseDir, "abc123", items)
+	if report.Checked != 3 {
+		t.Errorf("Expected 3 checked files, got %d", report.Checked)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"app/package.json"}) || !reflect.DeepEqual(report.Modified, []string{"plugins/demo/index.js"}) {
+		t.Errorf("Unexpected result: missing=%v modified=%v", report.Missing, report.Modified)
+	}
+	if !reflect.DeepEqual(report.Extra, []string{"app/extra.js"}) {
+		t.Errorf("Generated files must not be reported as extra: %v", report.Extra)
+	}
+}
+
+// Test the console text of the startup stages
+func TestStageText(t *testing.T) {
+	if text := stageText(readiness.Report{Stage: "ready", Version: "1.3.2"}); text != "✓ LTTH 1.3.2 ist bereit" {
+		t.Errorf("Unexpected stage text: %q", text)
+	}
+	if text := stageText(readiness.Report{Stage: "listening", Port: 3001}); text != "🌐 Server lauscht auf Port 3001..." {
+		t.Errorf("Unexpected stage text: %q", text)
+	}
+}
+
+// Test the console hint for a recognised crash cause
+func TestCrashHint(t *testing.T) {
+	if cause, fix := crashHint(crash.Analysis{Cause: crash.ModuleVersion, Module: "better-sqlite3"}); cause == "" || !strings.Contains(fix, "npm rebuild better-sqlite3") {
+		t.Errorf("Unexpected hint %q / %q", cause, fix)
+	}
+	if cause, _ := crashHint(crash.Analysis{Cause: crash.Unknown}); cause != "" {
+		t.Errorf("Expected no hint for an unknown cause, got %q", cause)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"archive/zip"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
@@ -12,8 +13,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
+	"github.com/Loggableim/pupcidslittletiktokhelper/internal/events"
+	"github.com/Loggableim/pupcidslittletiktokhelper/internal/netconf"
 	"github.com/pkg/browser"
 )
 
@@ -28,9 +32,11 @@ const (
 
 type CloudLauncher struct {
 	baseDir    string
+	network    netconf.Settings // Proxy and CA bundle from launcher-settings.json
+	mutex      sync.Mutex // Guards progress and status
 	progress   int
 	status     string
-	clients    map[chan string]bool
+	events     *events.Broker // Server-sent events to the splash screen
 	logger     *log.Logger
 }
 
@@ -38,33 +44,25 @@ func NewCloudLauncher() *CloudLauncher {
 	return &CloudLauncher{
 		status:   "Initialisiere Cloud Launcher...",
 		progress: 0,
-		clients:  make(map[chan string]bool),
+		events:   events.NewBroker(),
 		logger:   log.New(os.Stdout, "[LTTH Cloud] ", log.LstdFlags),
 	}
 }
 
 func (cl *CloudLauncher) updateProgress(value int, status string) {
+	cl.mutex.Lock()
 	cl.progress = value
 	cl.status = status
+	cl.mutex.Unlock()
 	cl.logger.Printf("[%d%%] %s\n", value, status)
 	
-	msg := fmt.Sprintf(`{"progress": %d, "status": "%s"}`, value, status)
-	for client := range cl.clients {
-		select {
-		case client <- msg:
-		default:
-		}
-	}
+	msg, _ := json.Marshal(map[string]interface{}{"progress": value, "status": status})
+	cl.events.Publish(events.Progress, string(msg))
 }
 
 func (cl *CloudLauncher) sendError(errMsg string) {
-	msg := fmt.Sprintf(`{"error": "%s"}`, errMsg)
-	for client := range cl.clients {
-		select {
-		case client <- msg:
-		default:
-		}
-	}
+	msg, _ := json.Marshal(map[string]interface{}{"error": errMsg})
+	cl.events.Publish(events.Error, string(msg))
 }
 
 // Serve the splash screen
@@ -95,27 +93,12 @@ func (cl *CloudLauncher) serveSplash(w http.ResponseWriter, r *http.Request) {
 
 // SSE endpoint for progress updates
 func (cl *CloudLauncher) handleSSE(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-
-	clientChan := make(chan string)
-	cl.clients[clientChan] = true
-	defer func() {
-		delete(cl.clients, clientChan)
-		close(clientChan)
-	}()
-
-	// Send initial status
-	initialMsg := fmt.Sprintf(`{"progress": %d, "status": "%s"}`, cl.progress, cl.status)
-	fmt.Fprintf(w, "data: %s\n\n", initialMsg)
-	w.(http.Flusher).Flush()
-
-	// Listen for updates
-	for msg := range clientChan {
-		fmt.Fprintf(w, "data: %s\n\n", msg)
-		w.(http.Flusher).Flush()
-	}
+	events.Serve(w, r, cl.events, func() string {
+		cl.mutex.Lock()
+		defer cl.mutex.Unlock()
+		msg, _ := json.Marshal(map[string]interface{}{"progress": cl.progress, "status": cl.status})
+		return string(msg)
+	})
 }
 
 // Download repository as ZIP from GitHub
@@ -129,7 +112,11 @@ func (cl *CloudLauncher) downloadRepository() error {
 	cl.logger.Printf("Downloading from: %s\n", zipURL)
 	
 	// Download the ZIP file
-	resp, err := http.Get(zipURL)
+	client, err := cl.network.HTTPClient(0)
+	if err != nil {
+		cl.logger.Printf("CA bundle could not be loaded: %v\n", err)
+	}
+	resp, err := client.Get(zipURL)
 	if err != nil {
 		return fmt.Errorf("Download fehlgeschlagen: %v", err)
 	}
@@ -257,6 +244,7 @@ func (cl *CloudLauncher) installDependencies(appDir string) error {
 	}
 	
 	cmd.Dir = appDir
+	cmd.Env = append(os.Environ(), cl.network.ChildEnv()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	
@@ -275,6 +263,7 @@ func (cl *CloudLauncher) startApplication(nodePath, appDir string) error {
 	launchJS := filepath.Join(appDir, "launch.js")
 	cmd := exec.Command(nodePath, launchJS)
 	cmd.Dir = appDir
+	cmd.Env = append(os.Environ(), cl.network.ChildEnv()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -308,13 +297,18 @@ func (cl *CloudLauncher) run() error {
 	cl.baseDir = filepath.Dir(exePath)
 	cl.logger.Printf("Base directory: %s\n", cl.baseDir)
 	
+	cl.network, err = netconf.Load(filepath.Join(cl.baseDir, "launcher-settings.json"))
+	if err != nil {
+		cl.logger.Printf("Invalid launcher-settings.json, using system proxy settings: %v\n", err)
+	}
+	
 	// Start HTTP server in background
 	http.HandleFunc("/", cl.serveSplash)
 	http.HandleFunc("/events", cl.handleSSE)
 	
 	go func() {
-		cl.logger.Println("Starting web server on :8765")
-		if err := http.ListenAndServe(":8765", nil); err != nil {
+		cl.logger.Println("Starting web server on 127.0.0.1:8765")
+		if err := http.ListenAndServe("127.0.0.1:8765", nil); err != nil {
 			cl.logger.Printf("HTTP server error: %v\n", err)
 		}
 	}()
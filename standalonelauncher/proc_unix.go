@@ -0,0 +1,30 @@
+//go:build !windows
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// processTree needs no state on Unix, the process group ID equals the PID of the command
+type processTree struct{}
+
+// prepareProcessTree makes cmd the leader of a new process group
+func prepareProcessTree(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+func attachProcessTree(cmd *exec.Cmd) (*processTree, error) {
+	return &processTree{}, nil
+}
+
+// kill signals the process group; a negative PID addresses the whole group
+func (t *processTree) kill(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func (t *processTree) close() {}
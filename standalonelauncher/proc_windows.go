@@ -0,0 +1,103 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processTree holds the Job Object containing the command and all its descendants.
+// job is zero if the process could not be assigned, e.g. on Windows 7 inside another job.
+type processTree struct {
+	job windows.Handle
+}
+
+// prepareProcessTree starts cmd suspended, so it cannot spawn children before it is in the job
+func prepareProcessTree(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+}
+
+// attachProcessTree assigns the suspended process to a new job and resumes it
+func attachProcessTree(cmd *exec.Cmd) (*processTree, error) {
+	pid := uint32(cmd.Process.Pid)
+	tree := &processTree{}
+	if job, err := windows.CreateJobObject(nil, nil); err == nil {
+		if assignToJob(job, pid) == nil {
+			tree.job = job
+		} else {
+			windows.CloseHandle(job)
+		}
+	}
+	if err := resumeProcess(pid); err != nil {
+		tree.close()
+		return nil, err
+	}
+	return tree, nil
+}
+
+func assignToJob(job windows.Handle, pid uint32) error {
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, pid)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(process)
+	return windows.AssignProcessToJobObject(job, process)
+}
+
+// resumeProcess resumes the threads of a process created with CREATE_SUSPENDED.
+// exec.Cmd does not expose the thread handle, so the threads are looked up by PID.
+func resumeProcess(pid uint32) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	resumed := false
+	entry := windows.ThreadEntry32{}
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != pid {
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return err
+		}
+		_, err = windows.ResumeThread(thread)
+		windows.CloseHandle(thread)
+		if err != nil {
+			return err
+		}
+		resumed = true
+	}
+	if !resumed {
+		return fmt.Errorf("no thread found for process %d", pid)
+	}
+	return nil
+}
+
+// kill terminates every process in the job. Without a job it falls back to taskkill /T,
+// which only finds descendants whose parent is still running.
+func (t *processTree) kill(cmd *exec.Cmd) error {
+	if t != nil && t.job != 0 {
+		return windows.TerminateJobObject(t.job, 1)
+	}
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+func (t *processTree) close() {
+	if t.job != 0 {
+		windows.CloseHandle(t.job)
+		t.job = 0
+	}
+}